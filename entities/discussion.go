@@ -19,6 +19,9 @@ type Comment struct {
 	Body      string    `json:"body"`
 	Author    string    `json:"author"`
 	CreatedAt time.Time `json:"created_at"`
+	URL       string    `json:"url"`
+	IsAnswer  bool      `json:"is_answer"`
+	Replies   []Comment `json:"replies"`
 }
 
 type Category struct {
@@ -10,6 +10,7 @@ type Note struct {
 	CreatedAt time.Time `json:"created_at"`
 	Tags      []string  `json:"tags"`
 	Reactions []Reaction `json:"reactions"`
+	Images    []string   `json:"images"`
 }
 
 type Reaction struct {
@@ -0,0 +1,127 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheVersion is bumped whenever the cached Discussion layout changes, so
+// that stale caches are discarded instead of being decoded into wrong fields
+const cacheVersion = 4
+
+// discussionCache is the on-disk copy of all discussions of a repository
+type discussionCache struct {
+	Version     int                   `json:"version"`
+	Owner       string                `json:"owner"`
+	Repo        string                `json:"repo"`
+	SyncedAt    time.Time             `json:"synced_at"`
+	Discussions map[string]Discussion `json:"discussions"`
+}
+
+// SyncDiscussions returns all discussions of the repository, using the cache
+// file at cachePath to fetch only the discussions that changed since the last
+// sync. Passing full discards the cache and refetches everything.
+func (g *GitHubFetcher) SyncDiscussions(ctx context.Context, cachePath string, full bool) ([]Discussion, error) {
+	cache, err := loadCache(cachePath)
+	if err != nil {
+		fmt.Printf("Warning: Ignoring discussion cache: %v\n", err)
+	}
+	if full || cache == nil || cache.Owner != g.owner || cache.Repo != g.repo {
+		cache = &discussionCache{Discussions: make(map[string]Discussion)}
+	}
+
+	syncedAt := time.Now().UTC()
+	updated, total, err := g.fetchDiscussions(ctx, cache.Discussions)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, discussion := range updated {
+		cache.Discussions[discussion.ID] = discussion
+	}
+
+	// Discussions can only be detected as deleted by listing what still
+	// exists, which is only worth doing when the counts disagree
+	removed := 0
+	if total != len(cache.Discussions) {
+		ids, err := g.fetchDiscussionIDs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for id := range cache.Discussions {
+			if !ids[id] {
+				delete(cache.Discussions, id)
+				removed++
+			}
+		}
+	}
+
+	fmt.Printf("Synced discussions: %d updated, %d removed, %d total\n", len(updated), removed, len(cache.Discussions))
+
+	cache.Version = cacheVersion
+	cache.Owner = g.owner
+	cache.Repo = g.repo
+	cache.SyncedAt = syncedAt
+	if err := saveCache(cachePath, cache); err != nil {
+		return nil, err
+	}
+
+	discussions := make([]Discussion, 0, len(cache.Discussions))
+	for _, discussion := range cache.Discussions {
+		discussions = append(discussions, discussion)
+	}
+	if err := g.markPinned(ctx, discussions); err != nil {
+		return nil, err
+	}
+	sortDiscussions(discussions)
+
+	return discussions, nil
+}
+
+// loadCache reads the cache file. A missing file is not an error; it simply
+// yields no cache.
+func loadCache(path string) (*discussionCache, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	var cache discussionCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("failed to decode cache: %w", err)
+	}
+	if cache.Version != cacheVersion || cache.Discussions == nil {
+		return nil, nil
+	}
+
+	return &cache, nil
+}
+
+// saveCache atomically writes the cache file
+func saveCache(path string, cache *discussionCache) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+
+	return nil
+}
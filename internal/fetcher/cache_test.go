@@ -0,0 +1,279 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDiscussion is a discussion served by fakeRepo. It was created on the
+// day of January given by its number.
+type fakeDiscussion struct {
+	id        string
+	number    int
+	title     string
+	updatedAt time.Time
+}
+
+// fakeRepo is a repository served by a fake GraphQL server. It answers the
+// listing, ID and pinned discussion queries and records which it received.
+type fakeRepo struct {
+	mu          sync.Mutex
+	discussions []fakeDiscussion
+	// pageSize is the number of discussions per page, regardless of the
+	// page size the query asks for
+	pageSize int
+	requests []string
+}
+
+// newFakeRepo starts a fake GraphQL server for repo and returns a fetcher
+// that queries it
+func newFakeRepo(t *testing.T, discussions ...fakeDiscussion) (*fakeRepo, *GitHubFetcher) {
+	t.Helper()
+
+	repo := &fakeRepo{discussions: discussions, pageSize: 2}
+	server := httptest.NewServer(http.HandlerFunc(repo.serve))
+	t.Cleanup(server.Close)
+
+	return repo, NewGitHubFetcherWithClient(newFastClient(server.URL, 0), "octocat", "blog")
+}
+
+// update replaces the discussions of the repository
+func (r *fakeRepo) update(discussions ...fakeDiscussion) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.discussions = discussions
+}
+
+// takeRequests returns the kinds of the requests received since the last call
+func (r *fakeRepo) takeRequests() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	requests := r.requests
+	r.requests = nil
+	return requests
+}
+
+func (r *fakeRepo) serve(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Query     string
+		Variables map[string]interface{}
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var kind string
+	switch {
+	case strings.Contains(body.Query, "pinnedDiscussions"):
+		kind = "pinned"
+	case strings.Contains(body.Query, "orderBy"):
+		kind = "list"
+	case strings.Contains(body.Query, "discussions("):
+		kind = "ids"
+	default:
+		http.Error(w, "unexpected query", http.StatusBadRequest)
+		return
+	}
+	r.requests = append(r.requests, kind)
+
+	if kind == "pinned" {
+		writeData(w, map[string]interface{}{
+			"pinnedDiscussions": map[string]interface{}{"nodes": []interface{}{}},
+		})
+		return
+	}
+
+	discussions := append([]fakeDiscussion(nil), r.discussions...)
+	sort.Slice(discussions, func(i, j int) bool {
+		return discussions[i].updatedAt.After(discussions[j].updatedAt)
+	})
+
+	start := 0
+	if cursor, ok := body.Variables["cursor"].(string); ok {
+		start, _ = strconv.Atoi(cursor)
+	}
+	end := start + r.pageSize
+	if end > len(discussions) {
+		end = len(discussions)
+	}
+
+	nodes := []interface{}{}
+	for _, d := range discussions[start:end] {
+		if kind == "ids" {
+			nodes = append(nodes, map[string]interface{}{"id": d.id})
+			continue
+		}
+		nodes = append(nodes, map[string]interface{}{
+			"id":        d.id,
+			"number":    d.number,
+			"title":     d.title,
+			"body":      "Hello",
+			"author":    map[string]interface{}{"login": "octocat"},
+			"category":  map[string]interface{}{"id": "C1", "name": "General", "isAnswerable": false},
+			"labels":    map[string]interface{}{"nodes": []interface{}{}},
+			"comments":  map[string]interface{}{"nodes": []interface{}{}, "pageInfo": map[string]interface{}{"endCursor": "", "hasNextPage": false}},
+			"createdAt": time.Date(2024, 1, d.number, 0, 0, 0, 0, time.UTC).Format(time.RFC3339),
+			"updatedAt": d.updatedAt.Format(time.RFC3339),
+			"url":       fmt.Sprintf("https://github.com/octocat/blog/discussions/%d", d.number),
+		})
+	}
+
+	connection := map[string]interface{}{
+		"nodes":    nodes,
+		"pageInfo": map[string]interface{}{"endCursor": strconv.Itoa(end), "hasNextPage": end < len(discussions)},
+	}
+	if kind == "list" {
+		connection["totalCount"] = len(discussions)
+	}
+	writeData(w, map[string]interface{}{"discussions": connection})
+}
+
+// writeData answers a query with repository as the repository field
+func writeData(w http.ResponseWriter, repository map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": map[string]interface{}{"repository": repository},
+	})
+}
+
+// titles returns the titles of discussions
+func titles(discussions []Discussion) []string {
+	var titles []string
+	for _, discussion := range discussions {
+		titles = append(titles, discussion.Title)
+	}
+	return titles
+}
+
+func TestSyncDiscussions(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 10, 0, 0, 0, time.UTC) }
+	first := fakeDiscussion{id: "D1", number: 1, title: "First", updatedAt: day(1)}
+	second := fakeDiscussion{id: "D2", number: 2, title: "Second", updatedAt: day(2)}
+	third := fakeDiscussion{id: "D3", number: 3, title: "Third", updatedAt: day(3)}
+
+	repo, fetcher := newFakeRepo(t, first, second, third)
+	cachePath := filepath.Join(t.TempDir(), "discussions.json")
+
+	steps := []struct {
+		name string
+		// change modifies the repository before syncing, if set
+		change       func()
+		wantTitles   []string
+		wantRequests []string
+	}{
+		{
+			name:         "cold cache",
+			wantTitles:   []string{"Third", "Second", "First"},
+			wantRequests: []string{"list", "list", "pinned"},
+		},
+		{
+			name:         "unchanged",
+			wantTitles:   []string{"Third", "Second", "First"},
+			wantRequests: []string{"list", "pinned"},
+		},
+		{
+			name: "edited",
+			change: func() {
+				edited := second
+				edited.title = "Second, edited"
+				edited.updatedAt = day(4)
+				repo.update(first, edited, third)
+			},
+			wantTitles:   []string{"Third", "Second, edited", "First"},
+			wantRequests: []string{"list", "pinned"},
+		},
+		{
+			name: "deleted",
+			change: func() {
+				edited := second
+				edited.title = "Second, edited"
+				edited.updatedAt = day(4)
+				repo.update(edited, third)
+			},
+			wantTitles:   []string{"Third", "Second, edited"},
+			wantRequests: []string{"list", "ids", "pinned"},
+		},
+	}
+
+	for _, step := range steps {
+		if step.change != nil {
+			step.change()
+		}
+		discussions, err := fetcher.SyncDiscussions(context.Background(), cachePath, false)
+		if err != nil {
+			t.Fatalf("%s: SyncDiscussions() error = %v", step.name, err)
+		}
+		if got := titles(discussions); !reflect.DeepEqual(got, step.wantTitles) {
+			t.Errorf("%s: titles = %q, want %q", step.name, got, step.wantTitles)
+		}
+		if got := repo.takeRequests(); !reflect.DeepEqual(got, step.wantRequests) {
+			t.Errorf("%s: requests = %q, want %q", step.name, got, step.wantRequests)
+		}
+	}
+
+	cache, err := loadCache(cachePath)
+	if err != nil || cache == nil {
+		t.Fatalf("loadCache() = %v, %v, want the synced cache", cache, err)
+	}
+	if len(cache.Discussions) != 2 || cache.Owner != "octocat" || cache.Repo != "blog" {
+		t.Errorf("cache = %d discussions of %s/%s, want 2 of octocat/blog", len(cache.Discussions), cache.Owner, cache.Repo)
+	}
+}
+
+func TestSyncDiscussionsDiscardsOutdatedCache(t *testing.T) {
+	updatedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	repo, fetcher := newFakeRepo(t, fakeDiscussion{id: "D1", number: 1, title: "Fresh", updatedAt: updatedAt})
+
+	// A cache written before the layout changed, whose copy would otherwise
+	// be considered up to date
+	cachePath := filepath.Join(t.TempDir(), "discussions.json")
+	data, err := json.Marshal(discussionCache{
+		Version: cacheVersion - 1,
+		Owner:   "octocat",
+		Repo:    "blog",
+		Discussions: map[string]Discussion{
+			"D1": {ID: "D1", Number: 1, Title: "Stale", UpdatedAt: updatedAt},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cachePath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	discussions, err := fetcher.SyncDiscussions(context.Background(), cachePath, false)
+	if err != nil {
+		t.Fatalf("SyncDiscussions() error = %v", err)
+	}
+	if got, want := titles(discussions), []string{"Fresh"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("titles = %q, want %q", got, want)
+	}
+	if got, want := repo.takeRequests(), []string{"list", "pinned"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+
+	cache, err := loadCache(cachePath)
+	if err != nil || cache == nil {
+		t.Fatalf("loadCache() = %v, %v, want the rewritten cache", cache, err)
+	}
+	if cache.Version != cacheVersion {
+		t.Errorf("cache version = %d, want %d", cache.Version, cacheVersion)
+	}
+}
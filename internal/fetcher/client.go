@@ -0,0 +1,244 @@
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+	"golang.org/x/oauth2"
+)
+
+// githubGraphQLURL is the endpoint of the public GitHub GraphQL API
+const githubGraphQLURL = "https://api.github.com/graphql"
+
+// RateLimit is the GraphQL rate limit status. Queries that include a field
+// named RateLimit of this type report the current budget back to the Client.
+type RateLimit struct {
+	Cost      int
+	Remaining int
+	ResetAt   time.Time
+}
+
+// Client wraps a githubv4 client. It retries transient failures with jittered
+// exponential backoff and waits for the rate limit to reset when the
+// remaining budget runs low.
+type Client struct {
+	gql *githubv4.Client
+
+	// MinRemaining is the budget below which queries wait for the reset
+	MinRemaining int
+
+	rateLimit RateLimit
+}
+
+// NewClient creates a Client for the GitHub GraphQL API
+func NewClient(token string) *Client {
+	return NewClientWithURL(githubGraphQLURL, token)
+}
+
+// NewClientWithURL creates a Client for the GraphQL endpoint at url, such as
+// a GitHub Enterprise server or a local fake server
+func NewClientWithURL(url, token string) *Client {
+	src := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: token},
+	)
+	httpClient := &http.Client{
+		Transport: &retryTransport{
+			base:       &oauth2.Transport{Source: src, Base: http.DefaultTransport},
+			maxRetries: 5,
+			baseDelay:  time.Second,
+			maxDelay:   time.Minute,
+		},
+	}
+
+	return &Client{
+		gql:          githubv4.NewEnterpriseClient(url, httpClient),
+		MinRemaining: 50,
+	}
+}
+
+// Query executes a GraphQL query, waiting for the rate limit to reset first
+// if the remaining budget is too low
+func (c *Client) Query(ctx context.Context, q interface{}, variables map[string]interface{}) error {
+	if err := c.waitForBudget(ctx); err != nil {
+		return err
+	}
+
+	if err := c.gql.Query(ctx, q, variables); err != nil {
+		return err
+	}
+
+	c.observe(q)
+	return nil
+}
+
+// RateLimit returns the rate limit status reported by the last query
+func (c *Client) RateLimit() RateLimit {
+	return c.rateLimit
+}
+
+// waitForBudget sleeps until the rate limit resets when the budget left is
+// smaller than MinRemaining or than the cost of the previous query
+func (c *Client) waitForBudget(ctx context.Context) error {
+	rl := c.rateLimit
+	if rl.ResetAt.IsZero() || (rl.Remaining >= c.MinRemaining && rl.Remaining >= rl.Cost) {
+		return nil
+	}
+
+	wait := time.Until(rl.ResetAt)
+	if wait <= 0 {
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(rl.ResetAt) {
+		return fmt.Errorf("rate limit exhausted (%d remaining) until %s, after the deadline", rl.Remaining, rl.ResetAt.Format(time.RFC3339))
+	}
+
+	fmt.Printf("Rate limit low (%d remaining), waiting until %s\n", rl.Remaining, rl.ResetAt.Format(time.RFC3339))
+	if err := sleepContext(ctx, wait); err != nil {
+		return err
+	}
+
+	c.rateLimit = RateLimit{}
+	return nil
+}
+
+// observe records the rate limit reported in the RateLimit field of q, if any
+func (c *Client) observe(q interface{}) {
+	v := reflect.ValueOf(q)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	field := v.FieldByName("RateLimit")
+	if !field.IsValid() {
+		return
+	}
+
+	if rl, ok := field.Interface().(RateLimit); ok && !rl.ResetAt.IsZero() {
+		c.rateLimit = rl
+	}
+}
+
+// retryTransport retries requests that failed with a network error, a 5xx
+// status or a rate limit response
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 && req.Body != nil {
+			if req.GetBody == nil {
+				return nil, fmt.Errorf("cannot retry request without GetBody")
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(r)
+
+		delay, retry := t.retryDelay(ctx, resp, err, attempt)
+		if !retry || attempt >= t.maxRetries {
+			return resp, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryDelay reports whether a response should be retried and how long to
+// wait before doing so
+func (t *retryTransport) retryDelay(ctx context.Context, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if err != nil {
+		// Errors caused by the context itself are final
+		return t.backoff(attempt), ctx.Err() == nil
+	}
+
+	switch {
+	case resp.StatusCode >= 500:
+		return t.backoff(attempt), true
+
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		if after := resp.Header.Get("Retry-After"); after != "" {
+			if seconds, err := strconv.Atoi(after); err == nil {
+				return time.Duration(seconds) * time.Second, true
+			}
+		}
+
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+				return time.Until(time.Unix(reset, 0)) + time.Second, true
+			}
+		}
+
+		// Secondary rate limits are only identifiable by their message
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if strings.Contains(strings.ToLower(string(body)), "secondary rate limit") {
+			// GitHub asks to wait at least a minute without Retry-After
+			delay := t.backoff(attempt)
+			if delay < time.Minute {
+				delay = time.Minute
+			}
+			return delay, true
+		}
+	}
+
+	return 0, false
+}
+
+// backoff returns the jittered exponential backoff delay for an attempt
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	delay := t.baseDelay << uint(attempt)
+	if delay <= 0 || delay > t.maxDelay {
+		delay = t.maxDelay
+	}
+	// Equal jitter: half fixed, half random
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// sleepContext sleeps for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,180 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// viewerQuery is a minimal query that reports the rate limit
+type viewerQuery struct {
+	RateLimit RateLimit
+	Viewer    struct {
+		Login string
+	}
+}
+
+// fakeGraphQL starts a server that answers the n-th request (starting at 1)
+// with handle, and counts the requests it received
+func fakeGraphQL(t *testing.T, handle func(w http.ResponseWriter, n int)) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handle(w, int(atomic.AddInt32(&requests, 1)))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+// writeViewer answers a viewerQuery with the given rate limit budget
+func writeViewer(w http.ResponseWriter, remaining int, resetAt time.Time) {
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"data":{"rateLimit":{"cost":1,"remaining":%d,"resetAt":%q},"viewer":{"login":"octocat"}}}`,
+		remaining, resetAt.UTC().Format(time.RFC3339))
+}
+
+// newFastClient creates a Client for url whose retries back off in
+// milliseconds instead of seconds
+func newFastClient(url string, maxRetries int) *Client {
+	httpClient := &http.Client{
+		Transport: &retryTransport{
+			base:       http.DefaultTransport,
+			maxRetries: maxRetries,
+			baseDelay:  10 * time.Millisecond,
+			maxDelay:   50 * time.Millisecond,
+		},
+	}
+
+	return &Client{
+		gql:          githubv4.NewEnterpriseClient(url, httpClient),
+		MinRemaining: 50,
+	}
+}
+
+func TestClientRetriesServerErrors(t *testing.T) {
+	server, requests := fakeGraphQL(t, func(w http.ResponseWriter, n int) {
+		if n < 3 {
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+			return
+		}
+		writeViewer(w, 5000, time.Now().Add(time.Hour))
+	})
+
+	var q viewerQuery
+	if err := newFastClient(server.URL, 5).Query(context.Background(), &q, nil); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if got := atomic.LoadInt32(requests); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+	if q.Viewer.Login != "octocat" {
+		t.Errorf("login = %q, want octocat", q.Viewer.Login)
+	}
+}
+
+func TestClientRetriesSecondaryRateLimit(t *testing.T) {
+	server, requests := fakeGraphQL(t, func(w http.ResponseWriter, n int) {
+		if n == 1 {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, `{"message":"You have exceeded a secondary rate limit."}`, http.StatusForbidden)
+			return
+		}
+		writeViewer(w, 5000, time.Now().Add(time.Hour))
+	})
+
+	start := time.Now()
+	var q viewerQuery
+	if err := NewClientWithURL(server.URL, "token").Query(context.Background(), &q, nil); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if got := atomic.LoadInt32(requests); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want at least the Retry-After of 1s", elapsed)
+	}
+}
+
+func TestClientWaitsForRateLimitReset(t *testing.T) {
+	// resetAt is sent with second precision, so round it up
+	resetAt := time.Now().Add(time.Second).Truncate(time.Second).Add(time.Second)
+	server, requests := fakeGraphQL(t, func(w http.ResponseWriter, n int) {
+		writeViewer(w, 1, resetAt)
+	})
+
+	client := newFastClient(server.URL, 0)
+	var q viewerQuery
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatalf("first Query() error = %v", err)
+	}
+	if got := client.RateLimit().Remaining; got != 1 {
+		t.Fatalf("observed remaining = %d, want 1", got)
+	}
+
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatalf("second Query() error = %v", err)
+	}
+	if time.Now().Before(resetAt) {
+		t.Errorf("second query ran before the reset at %s", resetAt.Format(time.RFC3339))
+	}
+	if got := atomic.LoadInt32(requests); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestClientGivesUpAtDeadline(t *testing.T) {
+	t.Run("retries", func(t *testing.T) {
+		server, requests := fakeGraphQL(t, func(w http.ResponseWriter, n int) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		var q viewerQuery
+		if err := newFastClient(server.URL, 1000).Query(ctx, &q, nil); err == nil {
+			t.Fatal("Query() succeeded, want an error")
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("gave up after %v, want shortly after the 200ms deadline", elapsed)
+		}
+		if got := atomic.LoadInt32(requests); got < 2 {
+			t.Errorf("requests = %d, want retries before the deadline", got)
+		}
+	})
+
+	t.Run("rate limit reset after deadline", func(t *testing.T) {
+		server, requests := fakeGraphQL(t, func(w http.ResponseWriter, n int) {
+			writeViewer(w, 0, time.Now().Add(time.Hour))
+		})
+
+		client := newFastClient(server.URL, 0)
+		var q viewerQuery
+		if err := client.Query(context.Background(), &q, nil); err != nil {
+			t.Fatalf("first Query() error = %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		start := time.Now()
+		if err := client.Query(ctx, &q, nil); err == nil {
+			t.Fatal("second Query() succeeded, want an error")
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("gave up after %v, want immediately", elapsed)
+		}
+		if got := atomic.LoadInt32(requests); got != 1 {
+			t.Errorf("requests = %d, want 1", got)
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+
+	"pure/entities"
+)
+
+// pageInfo is the cursor information of a GraphQL connection
+type pageInfo struct {
+	EndCursor   string
+	HasNextPage bool
+}
+
+// replyNode is a reply to a discussion comment as returned by the GraphQL API
+type replyNode struct {
+	ID     string
+	Body   string
+	Author struct {
+		Login string
+	}
+	CreatedAt   time.Time
+	URL         string
+	IsMinimized bool
+	IsAnswer    bool
+}
+
+// commentNode is a top-level discussion comment with its first page of replies.
+// The page sizes are kept small so that a page of 100 discussions stays well
+// below the GraphQL node limit; longer threads are paginated separately.
+type commentNode struct {
+	replyNode
+	Replies struct {
+		Nodes    []replyNode
+		PageInfo pageInfo
+	} `graphql:"replies(first: 20)"`
+}
+
+// fetchComments fetches the remaining comments of a discussion after cursor
+func (g *GitHubFetcher) fetchComments(ctx context.Context, number int, cursor string) ([]commentNode, error) {
+	var query struct {
+		RateLimit  RateLimit
+		Repository struct {
+			Discussion struct {
+				Comments struct {
+					Nodes    []commentNode
+					PageInfo pageInfo
+				} `graphql:"comments(first: 50, after: $cursor)"`
+			} `graphql:"discussion(number: $number)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+
+	variables := map[string]interface{}{
+		"owner":  githubv4.String(g.owner),
+		"name":   githubv4.String(g.repo),
+		"number": githubv4.Int(number),
+		"cursor": githubv4.String(cursor),
+	}
+
+	var comments []commentNode
+	for {
+		if err := g.client.Query(ctx, &query, variables); err != nil {
+			return nil, fmt.Errorf("failed to fetch comments of discussion %d: %w", number, err)
+		}
+
+		comments = append(comments, query.Repository.Discussion.Comments.Nodes...)
+
+		if !query.Repository.Discussion.Comments.PageInfo.HasNextPage {
+			break
+		}
+
+		variables["cursor"] = githubv4.String(query.Repository.Discussion.Comments.PageInfo.EndCursor)
+	}
+
+	return comments, nil
+}
+
+// fetchReplies fetches the remaining replies of a discussion comment after cursor
+func (g *GitHubFetcher) fetchReplies(ctx context.Context, commentID, cursor string) ([]replyNode, error) {
+	var query struct {
+		RateLimit RateLimit
+		Node      struct {
+			DiscussionComment struct {
+				Replies struct {
+					Nodes    []replyNode
+					PageInfo pageInfo
+				} `graphql:"replies(first: 100, after: $cursor)"`
+			} `graphql:"... on DiscussionComment"`
+		} `graphql:"node(id: $id)"`
+	}
+
+	variables := map[string]interface{}{
+		"id":     githubv4.ID(commentID),
+		"cursor": githubv4.String(cursor),
+	}
+
+	var replies []replyNode
+	for {
+		if err := g.client.Query(ctx, &query, variables); err != nil {
+			return nil, fmt.Errorf("failed to fetch replies of comment %s: %w", commentID, err)
+		}
+
+		replies = append(replies, query.Node.DiscussionComment.Replies.Nodes...)
+
+		if !query.Node.DiscussionComment.Replies.PageInfo.HasNextPage {
+			break
+		}
+
+		variables["cursor"] = githubv4.String(query.Node.DiscussionComment.Replies.PageInfo.EndCursor)
+	}
+
+	return replies, nil
+}
+
+// buildComments converts comment nodes into threaded comments, fetching any
+// replies that did not fit into the first page. Minimized comments are dropped.
+func (g *GitHubFetcher) buildComments(ctx context.Context, nodes []commentNode) ([]entities.Comment, error) {
+	var comments []entities.Comment
+	for _, node := range nodes {
+		if node.IsMinimized {
+			continue
+		}
+
+		replies := node.Replies.Nodes
+		if node.Replies.PageInfo.HasNextPage {
+			more, err := g.fetchReplies(ctx, node.ID, node.Replies.PageInfo.EndCursor)
+			if err != nil {
+				return nil, err
+			}
+			replies = append(replies, more...)
+		}
+
+		comment := toComment(node.replyNode)
+		for _, reply := range replies {
+			if reply.IsMinimized {
+				continue
+			}
+			comment.Replies = append(comment.Replies, toComment(reply))
+		}
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
+
+func toComment(node replyNode) entities.Comment {
+	return entities.Comment{
+		ID:        node.ID,
+		Body:      fixUnclosedCodeBlocks(node.Body),
+		Author:    node.Author.Login,
+		CreatedAt: node.CreatedAt,
+		URL:       node.URL,
+		IsAnswer:  node.IsAnswer,
+	}
+}
@@ -0,0 +1,155 @@
+package fetcher
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatter is the optional metadata block at the top of a discussion body
+type FrontMatter struct {
+	Slug        string    `yaml:"slug"`
+	Description string    `yaml:"description"`
+	Cover       string    `yaml:"cover"`
+	Canonical   string    `yaml:"canonical"`
+	Date        time.Time `yaml:"date"`
+	NoIndex     bool      `yaml:"noindex"`
+	// TOC overrides build.toc.enabled for the post when set
+	TOC *bool `yaml:"toc"`
+	// Draft and Unlisted set the publication state of the discussion
+	Draft    bool `yaml:"draft"`
+	Unlisted bool `yaml:"unlisted"`
+	// PublishDate schedules the discussion: it stays hidden until a build
+	// runs after this time
+	PublishDate time.Time `yaml:"publishDate"`
+	// Series puts the discussion into a series, read in SeriesOrder
+	Series      string `yaml:"series"`
+	SeriesOrder int    `yaml:"seriesOrder"`
+	// Featured overrides whether the discussion is featured on the homepage,
+	// which pinned discussions are by default
+	Featured *bool `yaml:"featured"`
+}
+
+// frontMatterMarker explicitly marks an HTML comment as front matter, as in
+// "<!-- front matter", for comments that use none of the known keys
+const frontMatterMarker = "front matter"
+
+// frontMatterKeys are the YAML keys of FrontMatter
+var frontMatterKeys = func() map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeOf(FrontMatter{})
+	for i := 0; i < t.NumField(); i++ {
+		if key, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ","); key != "" {
+			keys[key] = true
+		}
+	}
+	return keys
+}()
+
+// parseFrontMatter splits the front matter off the top of body and returns it
+// together with the remaining body. Two forms are accepted: YAML fenced by
+// "---" lines, and YAML inside an HTML comment, which GitHub hides when it
+// renders the discussion:
+//
+//	<!--
+//	slug: hello-world
+//	description: A short summary
+//	-->
+//
+// A comment is only taken as front matter when it sets one of the known keys
+// or starts with "<!-- front matter", so that ordinary comments such as
+// "<!-- TODO: fix links -->" stay in the body. A body without front matter is
+// returned unchanged.
+//
+// Errors are meant to be reported as warnings, the returned front matter and
+// body are usable either way: invalid front matter is left in the body, while
+// front matter with unknown keys, such as a misspelled "descripton", is
+// applied without them.
+func parseFrontMatter(body string) (FrontMatter, string, error) {
+	var fm FrontMatter
+
+	text := strings.ReplaceAll(body, "\r\n", "\n")
+	trimmed := strings.TrimLeft(text, " \t\n")
+
+	var block, rest string
+	comment, marked := false, false
+	switch {
+	case strings.HasPrefix(trimmed, "---\n"):
+		end := strings.Index(trimmed[4:], "\n---")
+		if end < 0 {
+			return fm, body, nil
+		}
+		block = trimmed[4 : 4+end]
+		rest = trimmed[4+end+4:]
+		// The closing fence must be a line of its own
+		if rest != "" && rest[0] != '\n' {
+			return fm, body, nil
+		}
+
+	case strings.HasPrefix(trimmed, "<!--"):
+		end := strings.Index(trimmed, "-->")
+		if end < 0 {
+			return fm, body, nil
+		}
+		block = strings.TrimSpace(trimmed[4:end])
+		rest = trimmed[end+3:]
+		comment = true
+		if first, after, _ := strings.Cut(block, "\n"); strings.EqualFold(strings.TrimSpace(first), frontMatterMarker) {
+			block = strings.TrimSpace(after)
+			marked = true
+		}
+		// The YAML may itself be fenced inside the comment
+		block = strings.TrimPrefix(block, "---\n")
+		block = strings.TrimSuffix(block, "\n---")
+
+	default:
+		return fm, body, nil
+	}
+
+	// Anything that isn't a YAML mapping, such as an ordinary comment or a
+	// thematic break, is regular content
+	var probe map[string]interface{}
+	if err := yaml.Unmarshal([]byte(block), &probe); err != nil || len(probe) == 0 {
+		return fm, body, nil
+	}
+	if comment && !marked && !hasFrontMatterKey(probe) {
+		return fm, body, nil
+	}
+
+	if err := yaml.Unmarshal([]byte(block), &fm); err != nil {
+		return FrontMatter{}, body, fmt.Errorf("invalid front matter: %w", err)
+	}
+
+	rest = strings.TrimLeft(rest, "\n")
+	if unknown := unknownFrontMatterKeys(probe); len(unknown) > 0 {
+		return fm, rest, fmt.Errorf("unknown front matter keys: %s", strings.Join(unknown, ", "))
+	}
+	return fm, rest, nil
+}
+
+// unknownFrontMatterKeys returns the keys of a YAML mapping that FrontMatter
+// does not have, sorted
+func unknownFrontMatterKeys(mapping map[string]interface{}) []string {
+	var unknown []string
+	for key := range mapping {
+		if !frontMatterKeys[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// hasFrontMatterKey reports whether a YAML mapping sets a known key
+func hasFrontMatterKey(mapping map[string]interface{}) bool {
+	for key := range mapping {
+		if frontMatterKeys[key] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package fetcher
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantSlug string
+		wantDesc string
+		wantDate time.Time
+		wantBody string
+		// wantErr is a substring of the expected error, empty for none
+		wantErr string
+	}{
+		{
+			name:     "fenced",
+			body:     "---\nslug: hello-world\ndescription: A short summary\n---\n\nHello",
+			wantSlug: "hello-world",
+			wantDesc: "A short summary",
+			wantBody: "Hello",
+		},
+		{
+			name:     "fenced after blank lines",
+			body:     "\n\n---\nslug: hello\n---\nHello",
+			wantSlug: "hello",
+			wantBody: "Hello",
+		},
+		{
+			name:     "fenced with date",
+			body:     "---\ndate: 2024-03-01T10:00:00Z\n---\nHello",
+			wantDate: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+			wantBody: "Hello",
+		},
+		{
+			name:     "html comment",
+			body:     "<!--\nslug: hello-world\ndescription: A short summary\n-->\nHello",
+			wantSlug: "hello-world",
+			wantDesc: "A short summary",
+			wantBody: "Hello",
+		},
+		{
+			name:     "html comment with fences",
+			body:     "<!--\n---\nslug: hello\n---\n-->\nHello",
+			wantSlug: "hello",
+			wantBody: "Hello",
+		},
+		{
+			name:     "html comment with marker",
+			body:     "<!-- front matter\nslug: hello\n-->\nHello",
+			wantSlug: "hello",
+			wantBody: "Hello",
+		},
+		{
+			name:     "ordinary html comment",
+			body:     "<!-- TODO: fix links -->\nHello",
+			wantBody: "<!-- TODO: fix links -->\nHello",
+		},
+		{
+			name:     "unclosed html comment",
+			body:     "<!--\nslug: hello\nHello",
+			wantBody: "<!--\nslug: hello\nHello",
+		},
+		{
+			name:     "horizontal rule",
+			body:     "---\n\nHello\n\n---\n\nWorld",
+			wantBody: "---\n\nHello\n\n---\n\nWorld",
+		},
+		{
+			name:     "horizontal rule without closing fence",
+			body:     "---\nHello",
+			wantBody: "---\nHello",
+		},
+		{
+			name:     "closing fence not on a line of its own",
+			body:     "---\nslug: hello\n----\nHello",
+			wantBody: "---\nslug: hello\n----\nHello",
+		},
+		{
+			name:     "no front matter",
+			body:     "Hello\n---\nslug: hello\n---",
+			wantBody: "Hello\n---\nslug: hello\n---",
+		},
+		{
+			name:     "unknown keys",
+			body:     "---\nslug: hello\ndescripton: typo\ntitle: Hi\n---\nHello",
+			wantSlug: "hello",
+			wantBody: "Hello",
+			wantErr:  "unknown front matter keys: descripton, title",
+		},
+		{
+			name:     "html comment with only unknown keys",
+			body:     "<!--\nauthor: someone\n-->\nHello",
+			wantBody: "<!--\nauthor: someone\n-->\nHello",
+		},
+		{
+			name:     "malformed yaml",
+			body:     "---\nslug: [hello\n---\nHello",
+			wantBody: "---\nslug: [hello\n---\nHello",
+		},
+		{
+			name:     "mistyped value",
+			body:     "---\nslug: hello\ndate: not a date\n---\nHello",
+			wantBody: "---\nslug: hello\ndate: not a date\n---\nHello",
+			wantErr:  "invalid front matter",
+		},
+		{
+			name:     "crlf fenced",
+			body:     "---\r\nslug: hello\r\ndescription: Summary\r\n---\r\n\r\nHello\r\nWorld",
+			wantSlug: "hello",
+			wantDesc: "Summary",
+			wantBody: "Hello\nWorld",
+		},
+		{
+			name:     "crlf html comment",
+			body:     "<!--\r\nslug: hello\r\n-->\r\nHello",
+			wantSlug: "hello",
+			wantBody: "Hello",
+		},
+		{
+			name:     "crlf without front matter",
+			body:     "Hello\r\nWorld",
+			wantBody: "Hello\r\nWorld",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, body, err := parseFrontMatter(tt.body)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("error = %v, want none", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+			if fm.Slug != tt.wantSlug {
+				t.Errorf("slug = %q, want %q", fm.Slug, tt.wantSlug)
+			}
+			if fm.Description != tt.wantDesc {
+				t.Errorf("description = %q, want %q", fm.Description, tt.wantDesc)
+			}
+			if !fm.Date.Equal(tt.wantDate) {
+				t.Errorf("date = %v, want %v", fm.Date, tt.wantDate)
+			}
+			if body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -3,27 +3,28 @@ package fetcher
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/shurcooL/githubv4"
-	"golang.org/x/oauth2"
+
+	"pure/entities"
 )
 
 // GitHubFetcher fetches data from GitHub Discussions
 type GitHubFetcher struct {
-	client *githubv4.Client
+	client *Client
 	owner  string
 	repo   string
 }
 
 // NewGitHubFetcher creates a new GitHubFetcher
 func NewGitHubFetcher(owner, repo, token string) *GitHubFetcher {
-	src := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
-	)
-	httpClient := oauth2.NewClient(context.Background(), src)
-	client := githubv4.NewClient(httpClient)
+	return NewGitHubFetcherWithClient(NewClient(token), owner, repo)
+}
 
+// NewGitHubFetcherWithClient creates a new GitHubFetcher using client
+func NewGitHubFetcherWithClient(client *Client, owner, repo string) *GitHubFetcher {
 	return &GitHubFetcher{
 		client: client,
 		owner:  owner,
@@ -45,40 +46,94 @@ type Discussion struct {
 	Labels []struct {
 		Name string
 	}
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	URL         string
+	Comments    []entities.Comment
+	FrontMatter FrontMatter
+	// Pinned reports whether the discussion is pinned in the repository
+	Pinned bool
+	// Answerable reports whether the discussion is in a Q&A category, where
+	// a comment can be chosen as the answer
+	Answerable     bool
+	IsAnswered     bool
+	Answer         *entities.Comment
+	AnswerChosenAt time.Time
+}
+
+// PublishedAt returns the publish date of the discussion, which is the
+// creation time unless overridden by the front matter date or publishDate
+func (d Discussion) PublishedAt() time.Time {
+	if !d.FrontMatter.Date.IsZero() {
+		return d.FrontMatter.Date
+	}
+	if !d.FrontMatter.PublishDate.IsZero() {
+		return d.FrontMatter.PublishDate
+	}
+	return d.CreatedAt
+}
+
+// discussionNode is a discussion as returned by the GraphQL API
+type discussionNode struct {
+	ID     string
+	Number int
+	Title  string
+	Body   string
+	Author struct {
+		Login string
+	}
+	Category struct {
+		ID           string
+		Name         string
+		IsAnswerable bool
+	}
+	Labels struct {
+		Nodes []struct {
+			Name string
+		}
+	} `graphql:"labels(first: 10)"`
+	Comments struct {
+		Nodes    []commentNode
+		PageInfo pageInfo
+	} `graphql:"comments(first: 20)"`
 	CreatedAt time.Time
+	UpdatedAt time.Time
 	URL       string
+
+	IsAnswered     bool
+	Answer         *replyNode
+	AnswerChosenAt *time.Time
 }
 
 // FetchDiscussions fetches discussions from GitHub
-func (g *GitHubFetcher) FetchDiscussions() ([]Discussion, error) {
+func (g *GitHubFetcher) FetchDiscussions(ctx context.Context) ([]Discussion, error) {
+	discussions, _, err := g.fetchDiscussions(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.markPinned(ctx, discussions); err != nil {
+		return nil, err
+	}
+	sortDiscussions(discussions)
+
+	return discussions, nil
+}
+
+// fetchDiscussions pages through the discussions of the repository, most
+// recently updated first. When known is non-nil, paging stops at the first
+// discussion whose updatedAt matches the known copy, since everything after it
+// is older and therefore unchanged as well. It also returns the total number of
+// discussions in the repository.
+func (g *GitHubFetcher) fetchDiscussions(ctx context.Context, known map[string]Discussion) ([]Discussion, int, error) {
 	var query struct {
+		RateLimit  RateLimit
 		Repository struct {
 			Discussions struct {
-				Nodes []struct {
-					ID        string
-					Number    int
-					Title     string
-					Body      string
-					Author    struct {
-						Login string
-					}
-					Category struct {
-						ID   string
-						Name string
-					}
-					Labels struct {
-						Nodes []struct {
-							Name string
-						}
-					} `graphql:"labels(first: 10)"`
-					CreatedAt time.Time
-					URL       string
-				}
-				PageInfo struct {
-					EndCursor   string
-					HasNextPage bool
-				}
-			} `graphql:"discussions(first: 100, after: $cursor)"`
+				TotalCount int
+				Nodes      []discussionNode
+				PageInfo   pageInfo
+			} `graphql:"discussions(first: 100, after: $cursor, orderBy: {field: UPDATED_AT, direction: DESC})"`
 		} `graphql:"repository(owner: $owner, name: $name)"`
 	}
 
@@ -91,34 +146,21 @@ func (g *GitHubFetcher) FetchDiscussions() ([]Discussion, error) {
 	var discussions []Discussion
 
 	for {
-		err := g.client.Query(context.Background(), &query, variables)
+		err := g.client.Query(ctx, &query, variables)
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch discussions: %w", err)
+			return nil, 0, fmt.Errorf("failed to fetch discussions: %w", err)
 		}
 
 		for _, node := range query.Repository.Discussions.Nodes {
-			labels := make([]struct{ Name string }, len(node.Labels.Nodes))
-			for i, label := range node.Labels.Nodes {
-				labels[i] = struct{ Name string }{Name: label.Name}
+			if cached, ok := known[node.ID]; ok && !node.UpdatedAt.After(cached.UpdatedAt) {
+				return discussions, query.Repository.Discussions.TotalCount, nil
 			}
 
-			discussions = append(discussions, Discussion{
-				ID:    node.ID,
-				Number: node.Number,
-				Title: node.Title,
-				Body:  node.Body,
-				Author: node.Author.Login,
-				Category: struct {
-					ID   string
-					Name string
-				}{
-					ID:   node.Category.ID,
-					Name: node.Category.Name,
-				},
-				Labels:    labels,
-				CreatedAt: node.CreatedAt,
-				URL:       node.URL,
-			})
+			discussion, err := g.toDiscussion(ctx, node)
+			if err != nil {
+				return nil, 0, err
+			}
+			discussions = append(discussions, discussion)
 		}
 
 		if !query.Repository.Discussions.PageInfo.HasNextPage {
@@ -128,5 +170,163 @@ func (g *GitHubFetcher) FetchDiscussions() ([]Discussion, error) {
 		variables["cursor"] = githubv4.String(query.Repository.Discussions.PageInfo.EndCursor)
 	}
 
-	return discussions, nil
-}
\ No newline at end of file
+	return discussions, query.Repository.Discussions.TotalCount, nil
+}
+
+// fetchDiscussionIDs fetches the IDs of all discussions in the repository
+func (g *GitHubFetcher) fetchDiscussionIDs(ctx context.Context) (map[string]bool, error) {
+	var query struct {
+		RateLimit  RateLimit
+		Repository struct {
+			Discussions struct {
+				Nodes []struct {
+					ID string
+				}
+				PageInfo pageInfo
+			} `graphql:"discussions(first: 100, after: $cursor)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+
+	variables := map[string]interface{}{
+		"owner":  githubv4.String(g.owner),
+		"name":   githubv4.String(g.repo),
+		"cursor": (*githubv4.String)(nil),
+	}
+
+	ids := make(map[string]bool)
+	for {
+		if err := g.client.Query(ctx, &query, variables); err != nil {
+			return nil, fmt.Errorf("failed to fetch discussion ids: %w", err)
+		}
+
+		for _, node := range query.Repository.Discussions.Nodes {
+			ids[node.ID] = true
+		}
+
+		if !query.Repository.Discussions.PageInfo.HasNextPage {
+			break
+		}
+
+		variables["cursor"] = githubv4.String(query.Repository.Discussions.PageInfo.EndCursor)
+	}
+
+	return ids, nil
+}
+
+// markPinned fetches the pinned discussions of the repository and marks them
+// among discussions. Pinning does not change updatedAt, so this is always
+// fetched anew.
+func (g *GitHubFetcher) markPinned(ctx context.Context, discussions []Discussion) error {
+	var query struct {
+		RateLimit  RateLimit
+		Repository struct {
+			PinnedDiscussions struct {
+				Nodes []struct {
+					Discussion struct {
+						ID string
+					}
+				}
+			} `graphql:"pinnedDiscussions(first: 10)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+
+	variables := map[string]interface{}{
+		"owner": githubv4.String(g.owner),
+		"name":  githubv4.String(g.repo),
+	}
+
+	if err := g.client.Query(ctx, &query, variables); err != nil {
+		return fmt.Errorf("failed to fetch pinned discussions: %w", err)
+	}
+
+	pinned := make(map[string]bool)
+	for _, node := range query.Repository.PinnedDiscussions.Nodes {
+		pinned[node.Discussion.ID] = true
+	}
+	for i := range discussions {
+		discussions[i].Pinned = pinned[discussions[i].ID]
+	}
+
+	return nil
+}
+
+// toDiscussion converts a discussion node into a Discussion, fetching any
+// comments that did not fit into the first page
+func (g *GitHubFetcher) toDiscussion(ctx context.Context, node discussionNode) (Discussion, error) {
+	labels := make([]struct{ Name string }, len(node.Labels.Nodes))
+	for i, label := range node.Labels.Nodes {
+		// Remove braces from label name if present
+		name := label.Name
+		if len(name) >= 2 && name[0] == '{' && name[len(name)-1] == '}' {
+			name = name[1 : len(name)-1]
+		}
+		labels[i] = struct{ Name string }{Name: name}
+	}
+
+	nodes := node.Comments.Nodes
+	if node.Comments.PageInfo.HasNextPage {
+		more, err := g.fetchComments(ctx, node.Number, node.Comments.PageInfo.EndCursor)
+		if err != nil {
+			return Discussion{}, err
+		}
+		nodes = append(nodes, more...)
+	}
+	comments, err := g.buildComments(ctx, nodes)
+	if err != nil {
+		return Discussion{}, err
+	}
+
+	frontMatter, body, err := parseFrontMatter(node.Body)
+	if err != nil {
+		fmt.Printf("Warning: Discussion %d: %v\n", node.Number, err)
+	}
+
+	// Fix unclosed code blocks in the content
+	fixedBody := fixUnclosedCodeBlocks(body)
+
+	var answer *entities.Comment
+	if node.Answer != nil && !node.Answer.IsMinimized {
+		comment := toComment(*node.Answer)
+		answer = &comment
+	}
+	var answerChosenAt time.Time
+	if node.AnswerChosenAt != nil {
+		answerChosenAt = *node.AnswerChosenAt
+	}
+
+	return Discussion{
+		ID:     node.ID,
+		Number: node.Number,
+		Title:  node.Title,
+		Body:   fixedBody,
+		Author: node.Author.Login,
+		Category: struct {
+			ID   string
+			Name string
+		}{
+			ID:   node.Category.ID,
+			Name: node.Category.Name,
+		},
+		Labels:      labels,
+		CreatedAt:   node.CreatedAt,
+		UpdatedAt:   node.UpdatedAt,
+		URL:         node.URL,
+		Comments:    comments,
+		FrontMatter: frontMatter,
+
+		Answerable:     node.Category.IsAnswerable,
+		IsAnswered:     node.IsAnswered,
+		Answer:         answer,
+		AnswerChosenAt: answerChosenAt,
+	}, nil
+}
+
+// sortDiscussions sorts discussions by publish date (newest first)
+func sortDiscussions(discussions []Discussion) {
+	sort.Slice(discussions, func(i, j int) bool {
+		if discussions[i].PublishedAt().Equal(discussions[j].PublishedAt()) {
+			return discussions[i].Number > discussions[j].Number
+		}
+		return discussions[i].PublishedAt().After(discussions[j].PublishedAt())
+	})
+}
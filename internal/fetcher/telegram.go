@@ -33,6 +33,7 @@ type TelegramMessage struct {
 	DateTime  time.Time
 	Tags      []string
 	Reactions []entities.Reaction
+	Images    []string
 }
 
 func NewTelegramFetcher(channel, host string) *TelegramFetcher {
@@ -135,6 +136,7 @@ var pageNotes []entities.Note
 					CreatedAt: msg.DateTime,
 					Tags:      msg.Tags,
 					Reactions: msg.Reactions,
+					Images:    msg.Images,
 				}
 				pageNotes = append(pageNotes, note)
 			}
@@ -190,7 +192,8 @@ func (f *TelegramFetcher) parseMessage(s *goquery.Selection) TelegramMessage {
 	}
 
 	// 2. 图片
-	imagesHTML := f.extractImages(s)
+	imagesHTML, images := f.extractImages(s)
+	msg.Images = images
 	if imagesHTML != "" {
 		contentParts = append(contentParts, imagesHTML)
 	}
@@ -378,14 +381,16 @@ func (f *TelegramFetcher) extractReply(s *goquery.Selection) string {
 	return ""
 }
 
-func (f *TelegramFetcher) extractImages(s *goquery.Selection) string {
-	var images []string
+// extractImages returns the HTML of the photos of a message and their URLs
+func (f *TelegramFetcher) extractImages(s *goquery.Selection) (string, []string) {
+	var images, urls []string
 
 	s.Find(".tgme_widget_message_photo_wrap").Each(func(i int, sel *goquery.Selection) {
 		style, _ := sel.Attr("style")
 		urlMatch := regexp.MustCompile(`url\(["']?(https?://[^"']+)["']?\)`).FindStringSubmatch(style)
 		if urlMatch != nil {
 			fullURL := urlMatch[1]
+			urls = append(urls, fullURL)
 
 			// Extract dimensions from style
 			widthMatch := regexp.MustCompile(`width:\s*(\d+)px`).FindStringSubmatch(style)
@@ -413,7 +418,7 @@ func (f *TelegramFetcher) extractImages(s *goquery.Selection) string {
 	})
 
 	if len(images) == 0 {
-		return ""
+		return "", nil
 	}
 
 	if len(images) > 1 {
@@ -421,10 +426,10 @@ func (f *TelegramFetcher) extractImages(s *goquery.Selection) string {
 		if len(images)%2 == 0 {
 			layout = "image-list-even"
 		}
-		return "<div class=\"note-images " + layout + "\">" + strings.Join(images, "") + "</div>"
+		return "<div class=\"note-images " + layout + "\">" + strings.Join(images, "") + "</div>", urls
 	}
 
-	return strings.Join(images, "")
+	return strings.Join(images, ""), urls
 }
 
 func (f *TelegramFetcher) extractVideo(s *goquery.Selection) string {
@@ -711,5 +716,6 @@ func (f *TelegramFetcher) FetchNote(id string) (entities.Note, error) {
 		CreatedAt: msg.DateTime,
 		Tags:      msg.Tags,
 		Reactions: msg.Reactions,
+		Images:    msg.Images,
 	}, nil
 }
\ No newline at end of file
@@ -0,0 +1,172 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"pure/entities"
+)
+
+// ArchiveYear is a year of an archive with its items grouped by month
+type ArchiveYear[T any] struct {
+	Year   int
+	URL    string
+	Count  int
+	Months []ArchiveMonth[T]
+}
+
+// ArchiveMonth is a month of an archive with its items, newest first
+type ArchiveMonth[T any] struct {
+	Year  int
+	Month time.Month
+	URL   string
+	Items []T
+}
+
+// buildArchive groups items sorted newest first by the year and month of
+// their date. Group pages live below baseURL at <year>/ and <year>/<month>/.
+func buildArchive[T any](baseURL string, items []T, date func(T) time.Time) []ArchiveYear[T] {
+	var years []ArchiveYear[T]
+	for _, item := range items {
+		t := date(item)
+		if len(years) == 0 || years[len(years)-1].Year != t.Year() {
+			years = append(years, ArchiveYear[T]{
+				Year: t.Year(),
+				URL:  fmt.Sprintf("%s%d/", baseURL, t.Year()),
+			})
+		}
+		year := &years[len(years)-1]
+		year.Count++
+
+		if len(year.Months) == 0 || year.Months[len(year.Months)-1].Month != t.Month() {
+			year.Months = append(year.Months, ArchiveMonth[T]{
+				Year:  t.Year(),
+				Month: t.Month(),
+				URL:   fmt.Sprintf("%s%d/%02d/", baseURL, t.Year(), t.Month()),
+			})
+		}
+		month := &year.Months[len(year.Months)-1]
+		month.Items = append(month.Items, item)
+	}
+	return years
+}
+
+// archivePage is a page of an archive: the archive itself, a year or a month
+type archivePage[T any] struct {
+	URL   string
+	Title string
+	Years []ArchiveYear[T]
+}
+
+// archivePages returns the pages of an archive, starting with the overview
+// at baseURL, followed by every year and month
+func archivePages[T any](baseURL, title string, years []ArchiveYear[T]) []archivePage[T] {
+	pages := []archivePage[T]{{URL: baseURL, Title: title, Years: years}}
+	for _, year := range years {
+		pages = append(pages, archivePage[T]{
+			URL:   year.URL,
+			Title: fmt.Sprintf("%s: %d", title, year.Year),
+			Years: []ArchiveYear[T]{year},
+		})
+		for _, month := range year.Months {
+			single := year
+			single.Count = len(month.Items)
+			single.Months = []ArchiveMonth[T]{month}
+			pages = append(pages, archivePage[T]{
+				URL:   month.URL,
+				Title: fmt.Sprintf("%s: %s %d", title, month.Month, year.Year),
+				Years: []ArchiveYear[T]{single},
+			})
+		}
+	}
+	return pages
+}
+
+// generateArchive writes the post archive at /archive/ with a page for every
+// year and month
+func (g *SiteGenerator) generateArchive(discussions []Post) error {
+	var posts []Post
+	for _, discussion := range discussions {
+		if g.config.Site.AboutID > 0 && discussion.Number == g.config.Site.AboutID {
+			continue
+		}
+		posts = append(posts, discussion)
+	}
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].PublishedAt().After(posts[j].PublishedAt())
+	})
+
+	years := buildArchive("/archive/", posts, Post.PublishedAt)
+	for _, page := range archivePages("/archive/", "Archive", years) {
+		err := g.renderPage(page.URL, func(w io.Writer) error {
+			data := struct {
+				Site     Config
+				Title    string
+				Overview []ArchiveYear[Post]
+				Years    []ArchiveYear[Post]
+			}{
+				Site:     g.config,
+				Title:    page.Title,
+				Overview: years,
+				Years:    page.Years,
+			}
+
+			if err := g.templates.ExecuteTemplate(w, "archive.html", data); err != nil {
+				return fmt.Errorf("failed to execute archive template: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		var lastmod time.Time
+		if len(page.Years) > 0 {
+			lastmod = postUpdated(page.Years[0].Months[0].Items[0])
+		}
+		g.addToSitemap(page.URL, lastmod)
+	}
+
+	return nil
+}
+
+// generateNotesArchive writes the memo archive at /memos/archive/ with a page
+// for every year and month. notes must be sorted newest first.
+func (g *NotesGenerator) generateNotesArchive(notes []entities.Note) error {
+	years := buildArchive("/memos/archive/", notes, func(note entities.Note) time.Time {
+		return note.CreatedAt
+	})
+	for _, page := range archivePages("/memos/archive/", "Memos Archive", years) {
+		err := g.renderPage(page.URL, func(w io.Writer) error {
+			data := struct {
+				Site     NotesConfig
+				Title    string
+				Overview []ArchiveYear[entities.Note]
+				Years    []ArchiveYear[entities.Note]
+			}{
+				Site:     g.config,
+				Title:    page.Title,
+				Overview: years,
+				Years:    page.Years,
+			}
+
+			if err := g.templates.ExecuteTemplate(w, "notes-archive.html", data); err != nil {
+				return fmt.Errorf("failed to execute memos archive template: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		var lastmod time.Time
+		if len(page.Years) > 0 {
+			lastmod = page.Years[0].Months[0].Items[0].CreatedAt
+		}
+		g.addToSitemap(page.URL, lastmod)
+	}
+
+	return nil
+}
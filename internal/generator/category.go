@@ -0,0 +1,147 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// defaultPostTemplate renders post pages of categories without a template
+const defaultPostTemplate = "post.html"
+
+// Category configures how the discussions of a GitHub Discussions category
+// are published, so that categories can serve as content types
+type Category struct {
+	// Name is the category name on GitHub, matched case-insensitively
+	Name string
+	// Title is the name shown on the site, defaulting to Name
+	Title string
+	// Slug is the URL name of the category pages, derived from Name if unset
+	Slug string
+	// Hidden leaves the discussions of the category out of the site
+	Hidden bool
+	// Template renders the post pages of the category instead of post.html
+	Template string
+}
+
+// categoryConfig returns the configuration of the named category. Categories
+// that are not configured are published with the defaults.
+func (g *SiteGenerator) categoryConfig(name string) Category {
+	for _, category := range g.config.Categories {
+		if strings.EqualFold(category.Name, name) {
+			return category
+		}
+	}
+	return Category{Name: name}
+}
+
+// categoryTitle returns the name of a category shown on the site
+func (g *SiteGenerator) categoryTitle(name string) string {
+	if title := g.categoryConfig(name).Title; title != "" {
+		return title
+	}
+	return name
+}
+
+// postTemplate returns the name of the template the post page is rendered with
+func (g *SiteGenerator) postTemplate(post Post) string {
+	if tmpl := g.categoryConfig(post.Category.Name).Template; tmpl != "" {
+		return tmpl
+	}
+	if post.Answerable {
+		return questionTemplate
+	}
+	return defaultPostTemplate
+}
+
+// checkCategoryTemplates reports categories whose template does not exist
+func (g *SiteGenerator) checkCategoryTemplates() error {
+	for _, category := range g.config.Categories {
+		if category.Template != "" && g.templates.Lookup(category.Template) == nil {
+			return fmt.Errorf("template %q of category %q not found", category.Template, category.Name)
+		}
+	}
+	return nil
+}
+
+// categorySummary is a category as listed on the category overview page
+type categorySummary struct {
+	Name  string
+	Title string
+	URL   string
+	Count int
+}
+
+// generateCategoryPages writes the category overview at /category/ and the
+// paginated post list of every category
+func (g *SiteGenerator) generateCategoryPages(discussions []Post) error {
+	categories := make(map[string][]Post)
+	for _, discussion := range discussions {
+		if g.config.Site.AboutID > 0 && discussion.Number == g.config.Site.AboutID {
+			continue
+		}
+		if name := discussion.Category.Name; name != "" {
+			categories[name] = append(categories[name], discussion)
+		}
+	}
+
+	var summaries []categorySummary
+	for name, posts := range categories {
+		summaries = append(summaries, categorySummary{
+			Name:  name,
+			Title: g.categoryTitle(name),
+			URL:   g.categoryURL(name),
+			Count: len(posts),
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Title < summaries[j].Title
+	})
+
+	if err := g.renderPage("/category/", func(w io.Writer) error {
+		data := struct {
+			Site       Config
+			Categories []categorySummary
+		}{
+			Site:       g.config,
+			Categories: summaries,
+		}
+
+		if err := g.templates.ExecuteTemplate(w, "categories.html", data); err != nil {
+			return fmt.Errorf("failed to execute categories template: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	g.addToSitemap("/category/", feedUpdated(discussions))
+
+	for _, summary := range summaries {
+		posts := categories[summary.Name]
+		err := paginate(g.renderPage, summary.URL, len(posts), g.config.Build.PostsPerPage, func(w io.Writer, start, end int, pagination Pagination) error {
+			data := struct {
+				Site        Config
+				Category    categorySummary
+				Discussions []Post
+				Pagination  Pagination
+			}{
+				Site:        g.config,
+				Category:    summary,
+				Discussions: posts[start:end],
+				Pagination:  pagination,
+			}
+			g.addToSitemap(pagination.PageURL(pagination.CurrentPage), feedUpdated(posts[start:end]))
+
+			if err := g.templates.ExecuteTemplate(w, "category.html", data); err != nil {
+				return fmt.Errorf("failed to execute category template: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to generate category page for %s: %w", summary.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package generator
+
+import "pure/internal/fetcher"
+
+// isFeatured reports whether the discussion is featured on the homepage:
+// discussions pinned on GitHub or listed in Build.Featured are, unless the
+// featured front matter key says otherwise
+func (g *SiteGenerator) isFeatured(discussion fetcher.Discussion) bool {
+	if featured := discussion.FrontMatter.Featured; featured != nil {
+		return *featured
+	}
+	if discussion.Pinned {
+		return true
+	}
+	for _, number := range g.config.Build.Featured {
+		if number == discussion.Number {
+			return true
+		}
+	}
+	return false
+}
+
+// featuredPosts returns the featured posts among posts, in their order
+func featuredPosts(posts []Post) []Post {
+	var featured []Post
+	for _, post := range posts {
+		if post.Featured {
+			featured = append(featured, post)
+		}
+	}
+	return featured
+}
@@ -0,0 +1,436 @@
+package generator
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	texttemplate "text/template"
+	"time"
+)
+
+// defaultFeedLimit is the number of posts in feeds when none is configured
+const defaultFeedLimit = 10
+
+// rssTemplate is the RSS 2.0 template used when templates/rss.xml is missing
+const rssTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+    <channel>
+        <title>{{xmlEscape .Title}}</title>
+        <description>{{xmlEscape .Description}}</description>
+        <link>{{.Link}}</link>
+        <atom:link href="{{.FeedURL}}" rel="self" type="application/rss+xml"/>
+        <lastBuildDate>{{.Updated}}</lastBuildDate>
+        <language>{{.Site.Language | default "en"}}</language>
+        <generator>Discussion Blog Generator</generator>
+        {{range .Discussions}}
+        <item>
+            <title>{{xmlEscape .Title}}</title>
+            <description>{{with .FrontMatter.Description}}{{xmlEscape .}}{{else}}{{truncateHTML .Body 200}}{{end}}</description>
+            {{- if $.FullContent}}
+            <content:encoded>{{xmlEscape (markdown .Body)}}</content:encoded>
+            {{- end}}
+            <link>{{.Permalink}}</link>
+            <guid isPermaLink="true">{{.Permalink}}</guid>
+            {{- range .Labels}}
+            <category>{{xmlEscape (trimBraces .Name)}}</category>
+            {{- end}}
+            <pubDate>{{.PublishedAt.Format "Mon, 02 Jan 2006 15:04:05 -0700"}}</pubDate>
+        </item>
+        {{end}}
+    </channel>
+</rss>`
+
+// feedInfo describes one of the RSS feeds of the site
+type feedInfo struct {
+	Title       string
+	Description string
+	// Link is the site-relative URL of the page the feed belongs to. The
+	// feed itself is written to rss.xml below it.
+	Link string
+}
+
+// generateFeeds writes the RSS, Atom and JSON feeds of the newest posts
+func (g *SiteGenerator) generateFeeds(discussions []Post) error {
+	posts := g.feedPosts(discussions)
+
+	site := feedInfo{
+		Title:       g.config.Site.Title,
+		Description: g.config.Site.Description,
+		Link:        "/",
+	}
+	if err := g.generateRSSFeed(posts, site); err != nil {
+		return fmt.Errorf("failed to generate RSS feed: %w", err)
+	}
+
+	if err := g.generateAtomFeed(posts); err != nil {
+		return fmt.Errorf("failed to generate Atom feed: %w", err)
+	}
+
+	if err := g.generateJSONFeed(posts); err != nil {
+		return fmt.Errorf("failed to generate JSON feed: %w", err)
+	}
+
+	if err := g.generateCategoryFeeds(discussions); err != nil {
+		return fmt.Errorf("failed to generate category feeds: %w", err)
+	}
+
+	return nil
+}
+
+// generateCategoryFeeds writes an RSS feed for every discussion category
+func (g *SiteGenerator) generateCategoryFeeds(discussions []Post) error {
+	categories := make(map[string][]Post)
+	for _, discussion := range discussions {
+		if name := discussion.Category.Name; name != "" {
+			categories[name] = append(categories[name], discussion)
+		}
+	}
+
+	for name, posts := range categories {
+		feed := feedInfo{
+			Title:       fmt.Sprintf("%s - %s", g.config.Site.Title, g.categoryTitle(name)),
+			Description: fmt.Sprintf("Posts in %s of %s", g.categoryTitle(name), g.config.Site.Title),
+			Link:        g.categoryURL(name),
+		}
+		if err := g.generateRSSFeed(g.feedPosts(posts), feed); err != nil {
+			return fmt.Errorf("failed to generate feed of category %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// feedPosts returns the newest posts, limited to the configured feed length
+func (g *SiteGenerator) feedPosts(discussions []Post) []Post {
+	posts := make([]Post, len(discussions))
+	copy(posts, discussions)
+
+	// Sort by publish date in descending order (newest first)
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].PublishedAt().After(posts[j].PublishedAt())
+	})
+
+	limit := g.config.Build.FeedLimit
+	if limit <= 0 {
+		limit = defaultFeedLimit
+	}
+	if len(posts) > limit {
+		posts = posts[:limit]
+	}
+
+	return posts
+}
+
+// postUpdated returns when a post was last changed
+func postUpdated(post Post) time.Time {
+	if post.UpdatedAt.After(post.PublishedAt()) {
+		return post.UpdatedAt
+	}
+	return post.PublishedAt()
+}
+
+// feedUpdated returns when the most recently changed post was changed
+func feedUpdated(posts []Post) time.Time {
+	var updated time.Time
+	for _, post := range posts {
+		if postUpdated(post).After(updated) {
+			updated = postUpdated(post)
+		}
+	}
+	return updated
+}
+
+// postAuthor returns the author of a post, falling back to the site author
+func (g *SiteGenerator) postAuthor(post Post) string {
+	if post.Author != "" {
+		return post.Author
+	}
+	return g.config.Site.Author
+}
+
+// postSummary returns the plain text summary of a post
+func postSummary(post Post) string {
+	if post.FrontMatter.Description != "" {
+		return post.FrontMatter.Description
+	}
+	return excerpt(post.Body, 200)
+}
+
+// postCategories returns the label names of a post
+func postCategories(post Post) []string {
+	var categories []string
+	for _, label := range post.Labels {
+		categories = append(categories, trimBraces(label.Name))
+	}
+	return categories
+}
+
+// generateRSSFeed writes the RSS feed of discussions to rss.xml below feed.Link
+func (g *SiteGenerator) generateRSSFeed(discussions []Post, feed feedInfo) error {
+	// Create RSS feed
+	rssPath := filepath.Join(g.outputDir, filepath.FromSlash(feed.Link), "rss.xml")
+	if err := os.MkdirAll(filepath.Dir(rssPath), 0755); err != nil {
+		return fmt.Errorf("failed to create feed directory: %w", err)
+	}
+	file, err := os.Create(rssPath)
+	if err != nil {
+		return fmt.Errorf("failed to create rss.xml: %w", err)
+	}
+	defer file.Close()
+
+	// Read the RSS template next to the HTML templates or use default
+	rssTemplateContent, err := os.ReadFile(filepath.Join(filepath.Dir(g.templateDir), "rss.xml"))
+	if err != nil {
+		rssTemplateContent = []byte(rssTemplate)
+	}
+
+	// For RSS XML, we need to use text/template to avoid HTML escaping of XML structure
+	tmpl, err := texttemplate.New("rss").Funcs(texttemplate.FuncMap{
+		"default": func(defaultValue, value interface{}) interface{} {
+			if value == nil || value == "" {
+				return defaultValue
+			}
+			return value
+		},
+		"truncate": func(s string, length int) string {
+			if len(s) <= length {
+				return s
+			}
+			return s[:length] + "..."
+		},
+		"truncateHTML": func(s string, length int) string {
+			return xmlEscape(excerpt(s, length))
+		},
+		"xmlEscape":  xmlEscape,
+		"absURL":     g.absURL,
+		"markdown":   renderMarkdown,
+		"trimBraces": trimBraces,
+	}).Parse(string(rssTemplateContent))
+	if err != nil {
+		return fmt.Errorf("failed to parse RSS template: %w", err)
+	}
+
+	data := struct {
+		Site struct {
+			Title       string
+			Description string
+			URL         string
+			Author      string
+			Email       string
+			Language    string
+		}
+		Title       string
+		Description string
+		Link        string
+		FeedURL     string
+		Updated     string
+		FullContent bool
+		Discussions []Post
+	}{
+		Site: struct {
+			Title       string
+			Description string
+			URL         string
+			Author      string
+			Email       string
+			Language    string
+		}{
+			Title:       g.config.Site.Title,
+			Description: g.config.Site.Description,
+			URL:         g.config.Site.URL,
+			Author:      g.config.Site.Author,
+			Email:       g.config.Site.Email,
+			Language:    g.config.Site.Language,
+		},
+		Title:       feed.Title,
+		Description: feed.Description,
+		Link:        g.absURL(feed.Link),
+		FeedURL:     g.absURL(feed.Link + "rss.xml"),
+		Updated:     feedUpdated(discussions).Format(time.RFC1123Z),
+		FullContent: g.config.Build.FeedFullContent,
+		Discussions: discussions,
+	}
+
+	if err := tmpl.Execute(file, data); err != nil {
+		return fmt.Errorf("failed to execute RSS template: %w", err)
+	}
+
+	return nil
+}
+
+// atomFeed is an Atom 1.0 feed document
+type atomFeed struct {
+	XMLName   xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title     string      `xml:"title"`
+	Subtitle  string      `xml:"subtitle,omitempty"`
+	ID        string      `xml:"id"`
+	Updated   string      `xml:"updated"`
+	Links     []atomLink  `xml:"link"`
+	Author    atomPerson  `xml:"author"`
+	Generator string      `xml:"generator"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email,omitempty"`
+	URI   string `xml:"uri,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Links      []atomLink     `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     atomPerson     `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+}
+
+func (g *SiteGenerator) generateAtomFeed(discussions []Post) error {
+	feed := atomFeed{
+		Title:    g.config.Site.Title,
+		Subtitle: g.config.Site.Description,
+		ID:       g.absURL("/"),
+		Updated:  feedUpdated(discussions).Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: g.absURL("/"), Rel: "alternate", Type: "text/html"},
+			{Href: g.absURL("/atom.xml"), Rel: "self", Type: "application/atom+xml"},
+		},
+		Author: atomPerson{
+			Name:  g.config.Site.Author,
+			Email: g.config.Site.Email,
+		},
+		Generator: "Discussion Blog Generator",
+	}
+
+	for _, discussion := range discussions {
+		entry := atomEntry{
+			Title:     discussion.Title,
+			ID:        discussion.Permalink,
+			Links:     []atomLink{{Href: discussion.Permalink, Rel: "alternate", Type: "text/html"}},
+			Published: discussion.PublishedAt().Format(time.RFC3339),
+			Updated:   postUpdated(discussion).Format(time.RFC3339),
+			Author:    atomPerson{Name: g.postAuthor(discussion)},
+			Summary:   &atomText{Type: "text", Body: postSummary(discussion)},
+		}
+		for _, category := range postCategories(discussion) {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		if g.config.Build.FeedFullContent {
+			entry.Content = &atomText{Type: "html", Body: renderMarkdown(discussion.Body)}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	output, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal Atom feed: %w", err)
+	}
+
+	atomPath := filepath.Join(g.outputDir, "atom.xml")
+	if err := os.WriteFile(atomPath, append([]byte(xml.Header), output...), 0644); err != nil {
+		return fmt.Errorf("failed to write atom.xml: %w", err)
+	}
+
+	return nil
+}
+
+// jsonFeed is a JSON Feed 1.1 document, see https://jsonfeed.org/version/1.1
+type jsonFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url"`
+	FeedURL     string           `json:"feed_url"`
+	Description string           `json:"description,omitempty"`
+	Language    string           `json:"language,omitempty"`
+	Authors     []jsonFeedAuthor `json:"authors,omitempty"`
+	Items       []jsonFeedItem   `json:"items"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html,omitempty"`
+	ContentText   string           `json:"content_text,omitempty"`
+	Summary       string           `json:"summary,omitempty"`
+	Image         string           `json:"image,omitempty"`
+	DatePublished string           `json:"date_published"`
+	DateModified  string           `json:"date_modified"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+func (g *SiteGenerator) generateJSONFeed(discussions []Post) error {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       g.config.Site.Title,
+		HomePageURL: g.absURL("/"),
+		FeedURL:     g.absURL("/feed.json"),
+		Description: g.config.Site.Description,
+		Language:    g.config.Site.Language,
+		Items:       []jsonFeedItem{},
+	}
+	if g.config.Site.Author != "" {
+		feed.Authors = []jsonFeedAuthor{{Name: g.config.Site.Author, URL: g.absURL("/")}}
+	}
+
+	for _, discussion := range discussions {
+		item := jsonFeedItem{
+			ID:            discussion.Permalink,
+			URL:           discussion.Permalink,
+			Title:         discussion.Title,
+			Summary:       postSummary(discussion),
+			Image:         discussion.FrontMatter.Cover,
+			DatePublished: discussion.PublishedAt().Format(time.RFC3339),
+			DateModified:  postUpdated(discussion).Format(time.RFC3339),
+			Authors:       []jsonFeedAuthor{{Name: g.postAuthor(discussion)}},
+			Tags:          postCategories(discussion),
+		}
+		// Every item needs either content_html or content_text
+		if g.config.Build.FeedFullContent {
+			item.ContentHTML = renderMarkdown(discussion.Body)
+		} else {
+			item.ContentText = postSummary(discussion)
+		}
+		feed.Items = append(feed.Items, item)
+	}
+
+	output, err := json.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON feed: %w", err)
+	}
+
+	feedPath := filepath.Join(g.outputDir, "feed.json")
+	if err := os.WriteFile(feedPath, output, 0644); err != nil {
+		return fmt.Errorf("failed to write feed.json: %w", err)
+	}
+
+	return nil
+}
@@ -3,8 +3,8 @@ package generator
 import (
 	"bytes"
 	"fmt"
+	gohtml "html"
 	"html/template"
-	texttemplate "text/template"
 	"io"
 	"os"
 	"path/filepath"
@@ -14,7 +14,6 @@ import (
 	"time"
 
 	"pure/internal/fetcher"
-	"pure/internal/utils"
 
 	"github.com/alecthomas/chroma/v2/formatters/html"
 	"github.com/alecthomas/chroma/v2/lexers"
@@ -51,11 +50,47 @@ type Telegram struct {
 	Host    string
 }
 
+// Build represents the build configuration.
+type Build struct {
+	// Permalink is the URL pattern of post pages, e.g. /:year/:month/:slug/
+	Permalink string
+	// Redirects are URL patterns of former post locations, such as
+	// /post/:number/, that get a redirect stub pointing to the permalink
+	Redirects []string
+	// Pinyin transliterates Chinese titles and tags into Pinyin slugs
+	Pinyin bool
+	// PostsPerPage is the page size of the index
+	PostsPerPage int
+	// TagPostsPerPage is the page size of tag pages, defaulting to PostsPerPage
+	TagPostsPerPage int
+	// FeedLimit is the number of newest posts included in feeds
+	FeedLimit int
+	// FeedFullContent includes the rendered post in feeds, not just a summary
+	FeedFullContent bool
+	// OGImages configures generated Open Graph card images
+	OGImages OGImages
+	// TOC configures the table of contents of posts
+	TOC TOC
+	// ReadingSpeed configures the estimated reading time of posts
+	ReadingSpeed ReadingSpeed
+	// RelatedPosts is the number of related posts listed on post pages,
+	// defaulting to 5. A negative number turns the list off.
+	RelatedPosts int
+	// Publishing configures drafts, unlisted and scheduled posts
+	Publishing Publishing
+	// Featured lists the numbers of discussions featured on the homepage in
+	// addition to the ones pinned on GitHub
+	Featured []int
+}
+
 // Config represents the site configuration
 type Config struct {
-	Site      Site
-	Github    Github
-	Telegram  Telegram
+	Site     Site
+	Github   Github
+	Telegram Telegram
+	Build    Build
+	// Categories configures how discussion categories are published
+	Categories []Category
 }
 
 // SiteGenerator generates static site files
@@ -64,10 +99,28 @@ type SiteGenerator struct {
 	templateDir string
 	outputDir   string
 	templates   *template.Template
+	tagSlugs    map[string]string
+
+	categorySlugs map[string]string
+	series        map[string]*Series
+	seriesNames   []string
+	sitemap       []sitemapURL
+	// sitemapPath is the site-relative path of the sitemap once written
+	sitemapPath string
+	// pages are the output paths of the pages written during this build
+	pages map[string]bool
+	// buildTime decides which scheduled posts are published
+	buildTime time.Time
 }
 
 // NewSiteGenerator creates a new SiteGenerator
 func NewSiteGenerator(config Config, templateDir, outputDir string) (*SiteGenerator, error) {
+	g := &SiteGenerator{
+		config:      config,
+		templateDir: templateDir,
+		outputDir:   outputDir,
+	}
+
 	// Define custom template functions
 	funcMap := template.FuncMap{
 		"default": func(defaultValue, value interface{}) interface{} {
@@ -83,35 +136,15 @@ func NewSiteGenerator(config Config, templateDir, outputDir string) (*SiteGenera
 			return s[:length] + "..."
 		},
 		"markdown": func(s string) template.HTML {
-			// Clean up line endings
-			s = strings.ReplaceAll(s, "\r\n", "\n")
-			s = strings.ReplaceAll(s, "\r", "\n")
-
-			// Convert markdown to HTML with Chroma
-			renderer := &ChromaRenderer{HTML: blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{
-				Flags: blackfriday.UseXHTML,
-			})}
-			extensions := blackfriday.CommonExtensions | blackfriday.AutoHeadingIDs | blackfriday.NoEmptyLineBeforeBlock
-			html := blackfriday.Run([]byte(s), blackfriday.WithRenderer(renderer), blackfriday.WithExtensions(extensions))
-			return template.HTML(html)
-		},
-		"trimBraces": func(s string) string {
-			s = strings.TrimPrefix(s, "{")
-			s = strings.TrimSuffix(s, "}")
-			return s
-		},
-		"truncateHTML": func(s string, length int) string {
-			// Convert markdown to HTML first
-			htmlContent := blackfriday.Run([]byte(s))
-			// Remove HTML tags
-			re := regexp.MustCompile("<[^>]*>")
-			plainText := re.ReplaceAllString(string(htmlContent), "")
-			// Truncate to specified length
-			if len(plainText) > length {
-				plainText = plainText[:length] + "..."
-			}
-			return plainText
+			return template.HTML(renderMarkdown(s))
 		},
+		"trimBraces":    trimBraces,
+		"truncateHTML":  excerpt,
+		"tagURL":        g.tagURL,
+		"categoryURL":   g.categoryURL,
+		"categoryTitle": g.categoryTitle,
+		"readingTime":   postReadingTime,
+		"wordCount":     postWordCount,
 	}
 
 	// Parse all templates from the template directory with custom functions
@@ -120,20 +153,26 @@ func NewSiteGenerator(config Config, templateDir, outputDir string) (*SiteGenera
 		return nil, fmt.Errorf("failed to parse templates: %w", err)
 	}
 
-	return &SiteGenerator{
-		config:      config,
-		templateDir: templateDir,
-		outputDir:   outputDir,
-		templates:   templates,
-	}, nil
+	g.templates = templates
+	if err := g.checkCategoryTemplates(); err != nil {
+		return nil, err
+	}
+
+	return g, nil
 }
 
 // ChromaRenderer is a custom Blackfriday renderer that uses Chroma for syntax highlighting
 type ChromaRenderer struct {
 	HTML blackfriday.Renderer
+	// HeadingAnchors adds a self-link to headings with an ID
+	HeadingAnchors bool
 }
 
 func (r *ChromaRenderer) RenderNode(w io.Writer, node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+	if node.Type == blackfriday.Heading && !entering && r.HeadingAnchors && node.HeadingID != "" {
+		writeHeadingAnchor(w, node.HeadingID)
+	}
+
 	if node.Type == blackfriday.CodeBlock {
 		var lang string
 		if node.CodeBlockData.Info != nil {
@@ -197,41 +236,97 @@ func (g *SiteGenerator) Generate(discussions []fetcher.Discussion) error {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
+	g.buildTime = time.Now()
+	posts := g.preparePosts(g.publishedDiscussions(discussions))
+	// Unlisted posts only get their own page
+	listed := listedPosts(posts)
+	g.sitemap = nil
+	g.sitemapPath = ""
+	g.pages = nil
+	if err := g.prepareTags(posts); err != nil {
+		return fmt.Errorf("failed to prepare tags: %w", err)
+	}
+	if err := g.prepareCategories(posts); err != nil {
+		return fmt.Errorf("failed to prepare categories: %w", err)
+	}
+	if err := g.prepareSeries(listed); err != nil {
+		return fmt.Errorf("failed to prepare series: %w", err)
+	}
+
 	// Generate Chroma CSS
 	if err := g.generateChromaCSS(); err != nil {
 		return fmt.Errorf("failed to generate chroma css: %w", err)
 	}
 
+	// Generate Open Graph images
+	if err := g.generateOGImages(posts); err != nil {
+		return fmt.Errorf("failed to generate OG images: %w", err)
+	}
+
 	// Generate index page
-	if err := g.generateIndexPage(discussions); err != nil {
+	if err := g.generateIndexPage(listed); err != nil {
 		return fmt.Errorf("failed to generate index page: %w", err)
 	}
 
 	// Generate individual post pages
-	if err := g.generatePostPages(discussions); err != nil {
+	if err := g.generatePostPages(posts); err != nil {
 		return fmt.Errorf("failed to generate post pages: %w", err)
 	}
 
 	// Generate about page if about_id is configured
-	if err := g.generateAboutPage(discussions); err != nil {
+	if err := g.generateAboutPage(posts); err != nil {
 		return fmt.Errorf("failed to generate about page: %w", err)
 	}
 
 	// Generate tag page
-	if err := g.generateTagPage(discussions); err != nil {
+	if err := g.generateTagPage(listed); err != nil {
 		return fmt.Errorf("failed to generate tag page: %w", err)
 	}
 
-	// Generate RSS feed
-	if err := g.generateRSSFeed(discussions); err != nil {
-		return fmt.Errorf("failed to generate RSS feed: %w", err)
+	// Generate category pages
+	if err := g.generateCategoryPages(listed); err != nil {
+		return fmt.Errorf("failed to generate category pages: %w", err)
+	}
+
+	// Generate the FAQ page from Q&A discussions
+	if err := g.generateFAQPage(listed); err != nil {
+		return fmt.Errorf("failed to generate faq page: %w", err)
+	}
+
+	// Generate series landing pages
+	if err := g.generateSeriesPages(); err != nil {
+		return fmt.Errorf("failed to generate series pages: %w", err)
+	}
+
+	// Generate archive pages by year and month
+	if err := g.generateArchive(listed); err != nil {
+		return fmt.Errorf("failed to generate archive: %w", err)
+	}
+
+	// Generate RSS, Atom and JSON feeds
+	if err := g.generateFeeds(listed); err != nil {
+		return fmt.Errorf("failed to generate feeds: %w", err)
 	}
 
-	// Generate search index
-	if err := g.generateSearchIndex(discussions); err != nil {
+	// Generate search index and page
+	if err := g.generateSearchIndex(listed); err != nil {
 		return fmt.Errorf("failed to generate search index: %w", err)
 	}
 
+	if err := g.generateSearchPage(); err != nil {
+		return fmt.Errorf("failed to generate search page: %w", err)
+	}
+
+	// Generate redirects from former post URLs
+	if err := g.generateRedirects(posts); err != nil {
+		return fmt.Errorf("failed to generate redirects: %w", err)
+	}
+
+	// Generate sitemap
+	if err := g.generateSitemap(); err != nil {
+		return fmt.Errorf("failed to generate sitemap: %w", err)
+	}
+
 	// Copy static assets
 	if err := g.copyStaticAssets(); err != nil {
 		return fmt.Errorf("failed to copy static assets: %w", err)
@@ -240,9 +335,9 @@ func (g *SiteGenerator) Generate(discussions []fetcher.Discussion) error {
 	return nil
 }
 
-func (g *SiteGenerator) generateIndexPage(discussions []fetcher.Discussion) error {
+func (g *SiteGenerator) generateIndexPage(discussions []Post) error {
 	// Filter out about page if about_id is configured
-	var filteredDiscussions []fetcher.Discussion
+	var filteredDiscussions []Post
 	for _, discussion := range discussions {
 		// Skip the discussion if it matches the about_id
 		if g.config.Site.AboutID > 0 && discussion.Number == g.config.Site.AboutID {
@@ -253,98 +348,48 @@ func (g *SiteGenerator) generateIndexPage(discussions []fetcher.Discussion) erro
 
 	// Use filtered discussions for index page generation
 	discussions = filteredDiscussions
+	featured := featuredPosts(discussions)
 
-	// Define posts per page
-	postsPerPage := 10
-
-	// Calculate total pages
-	totalPosts := len(discussions)
-	totalPages := (totalPosts + postsPerPage - 1) / postsPerPage
-
-	// Generate pages
-	for page := 1; page <= totalPages; page++ {
-		// Calculate start and end indices
-		start := (page - 1) * postsPerPage
-		end := start + postsPerPage
-		if end > totalPosts {
-			end = totalPosts
-		}
-
-		// Get posts for this page
-		pageDiscussions := discussions[start:end]
-
-		// Create page directory
-		var pageDir string
-		if page == 1 {
-			// First page goes to root directory (index page)
-			pageDir = g.outputDir
-		} else {
-			// Other pages go to /page/{page}/
-			pageDir = filepath.Join(g.outputDir, "page", fmt.Sprintf("%d", page))
-		}
-
-		if err := os.MkdirAll(pageDir, 0755); err != nil {
-			return fmt.Errorf("failed to create page directory: %w", err)
-		}
-
-		// Create index.html
-		indexPath := filepath.Join(pageDir, "index.html")
-		file, err := os.Create(indexPath)
-		if err != nil {
-			return fmt.Errorf("failed to create index.html: %w", err)
-		}
-		defer file.Close()
-
-		// Prepare data for template
+	return paginate(g.renderPage, "/", len(discussions), g.config.Build.PostsPerPage, func(w io.Writer, start, end int, pagination Pagination) error {
+		// Prepare data for template, featured posts only go above page 1
 		data := struct {
 			Site        Config
-			Discussions []fetcher.Discussion
-			Pagination  struct {
-				CurrentPage int
-				TotalPages  int
-				HasPrev     bool
-				HasNext     bool
-				PrevPage    int
-				NextPage    int
-			}
+			Featured    []Post
+			Discussions []Post
+			Pagination  Pagination
 		}{
 			Site:        g.config,
-			Discussions: pageDiscussions,
-			Pagination: struct {
-				CurrentPage int
-				TotalPages  int
-				HasPrev     bool
-				HasNext     bool
-				PrevPage    int
-				NextPage    int
-			}{
-				CurrentPage: page,
-				TotalPages:  totalPages,
-				HasPrev:     page > 1,
-				HasNext:     page < totalPages,
-				PrevPage:    page - 1,
-				NextPage:    page + 1,
-			},
+			Discussions: discussions[start:end],
+			Pagination:  pagination,
 		}
+		if pagination.CurrentPage == 1 {
+			data.Featured = featured
+		}
+		g.addToSitemap(pagination.PageURL(pagination.CurrentPage), feedUpdated(discussions[start:end]))
 
 		// Execute the index template
-		if err := g.templates.ExecuteTemplate(file, "index.html", data); err != nil {
+		if err := g.templates.ExecuteTemplate(w, "index.html", data); err != nil {
 			return fmt.Errorf("failed to execute index template: %w", err)
 		}
-	}
-
-	return nil
+		return nil
+	})
 }
 
-func (g *SiteGenerator) generatePostPages(discussions []fetcher.Discussion) error {
+func (g *SiteGenerator) generatePostPages(discussions []Post) error {
 	// Sort discussions by discussion number to ensure correct 'previous' and 'next'
+	discussions = append([]Post(nil), discussions...)
 	sort.Slice(discussions, func(i, j int) bool {
 		return discussions[i].Number < discussions[j].Number
 	})
+	related := g.relatedPosts(discussions)
+	listed := listedPosts(discussions)
 
-	for i, discussion := range discussions {
+	for _, discussion := range discussions {
 		// Create post directory
-		postDir := filepath.Join(g.outputDir, "post", fmt.Sprintf("%d", discussion.Number))
+		postDir, err := g.outputPath(discussion.Path)
+		if err != nil {
+			return fmt.Errorf("invalid permalink of discussion #%d: %w", discussion.Number, err)
+		}
 		if err := os.MkdirAll(postDir, 0755); err != nil {
 			return fmt.Errorf("failed to create post directory: %w", err)
 		}
@@ -357,40 +402,59 @@ func (g *SiteGenerator) generatePostPages(discussions []fetcher.Discussion) erro
 		}
 		defer file.Close()
 
-		// Determine previous and next discussions
-		var prevDiscussion *fetcher.Discussion
-		if i > 0 {
-			prevDiscussion = &discussions[i-1]
-		}
-
-		var nextDiscussion *fetcher.Discussion
-		if i < len(discussions)-1 {
-			nextDiscussion = &discussions[i+1]
+		// Determine previous and next discussions among the listed ones,
+		// unlisted posts are not linked from other posts
+		var prevDiscussion, nextDiscussion *Post
+		if !discussion.Unlisted {
+			i := sort.Search(len(listed), func(i int) bool {
+				return listed[i].Number >= discussion.Number
+			})
+			if i > 0 {
+				prevDiscussion = &listed[i-1]
+			}
+			if i < len(listed)-1 {
+				nextDiscussion = &listed[i+1]
+			}
 		}
 
 		// Prepare data for template
+		content, toc := g.postContent(discussion)
 		data := struct {
 			Site           Config
-			Discussion     fetcher.Discussion
-			PrevDiscussion *fetcher.Discussion
-			NextDiscussion *fetcher.Discussion
+			Discussion     Post
+			Content        template.HTML
+			TOC            []*Heading
+			Related        []Post
+			Series         *SeriesNav
+			PrevDiscussion *Post
+			NextDiscussion *Post
+			Meta           Meta
 		}{
 			Site:           g.config,
 			Discussion:     discussion,
+			Content:        content,
+			TOC:            toc,
+			Related:        related[discussion.Number],
+			Series:         g.seriesNav(discussion),
 			PrevDiscussion: prevDiscussion,
 			NextDiscussion: nextDiscussion,
+			Meta:           g.postMeta(discussion),
 		}
 
 		// Execute the post template
-		if err := g.templates.ExecuteTemplate(file, "post.html", data); err != nil {
+		if err := g.templates.ExecuteTemplate(file, g.postTemplate(discussion), data); err != nil {
 			return fmt.Errorf("failed to execute post template: %w", err)
 		}
+
+		if !discussion.noIndex() {
+			g.addToSitemap(discussion.Path, postUpdated(discussion))
+		}
 	}
 
 	return nil
 }
 
-func (g *SiteGenerator) generateTagPage(discussions []fetcher.Discussion) error {
+func (g *SiteGenerator) generateTagPage(discussions []Post) error {
 	// Collect all unique tags
 	tagMap := make(map[string]int)
 	for _, discussion := range discussions {
@@ -400,7 +464,10 @@ func (g *SiteGenerator) generateTagPage(discussions []fetcher.Discussion) error
 	}
 
 	// Create tags directory
-	tagsDir := filepath.Join(g.outputDir, "tags")
+	tagsDir, err := g.outputPath("/tags/")
+	if err != nil {
+		return err
+	}
 	if err := os.MkdirAll(tagsDir, 0755); err != nil {
 		return fmt.Errorf("failed to create tags directory: %w", err)
 	}
@@ -426,6 +493,7 @@ func (g *SiteGenerator) generateTagPage(discussions []fetcher.Discussion) error
 	if err := g.templates.ExecuteTemplate(file, "tags.html", data); err != nil {
 		return fmt.Errorf("failed to execute tags template: %w", err)
 	}
+	g.addToSitemap("/tags/", feedUpdated(discussions))
 
 	// Generate individual tag pages
 	for tag := range tagMap {
@@ -437,15 +505,9 @@ func (g *SiteGenerator) generateTagPage(discussions []fetcher.Discussion) error
 	return nil
 }
 
-func (g *SiteGenerator) generateTagPageForTag(tag string, discussions []fetcher.Discussion) error {
-	// Create tag directory
-	tagDir := filepath.Join(g.outputDir, "tags", tag)
-	if err := os.MkdirAll(tagDir, 0755); err != nil {
-		return fmt.Errorf("failed to create tag directory: %w", err)
-	}
-
+func (g *SiteGenerator) generateTagPageForTag(tag string, discussions []Post) error {
 	// Filter discussions by tag
-	var taggedDiscussions []fetcher.Discussion
+	var taggedDiscussions []Post
 	for _, discussion := range discussions {
 		for _, label := range discussion.Labels {
 			if label.Name == tag {
@@ -455,248 +517,46 @@ func (g *SiteGenerator) generateTagPageForTag(tag string, discussions []fetcher.
 		}
 	}
 
-	// Create index.html
-	tagPath := filepath.Join(tagDir, "index.html")
-	file, err := os.Create(tagPath)
-	if err != nil {
-		return fmt.Errorf("failed to create tag index.html: %w", err)
-	}
-	defer file.Close()
-
-	// Prepare data for template
-	data := struct {
-		Site        Config
-		Tag         string
-		Discussions []fetcher.Discussion
-	}{
-		Site:        g.config,
-		Tag:         tag,
-		Discussions: taggedDiscussions,
-	}
-
-	// Execute the tag template
-	if err := g.templates.ExecuteTemplate(file, "tag.html", data); err != nil {
-		return fmt.Errorf("failed to execute tag template: %w", err)
+	tagURL := g.tagURL(tag)
+	if _, err := g.checkPath(tagURL); err != nil {
+		return err
 	}
 
-	return nil
-}
-
-func (g *SiteGenerator) generateRSSFeed(discussions []fetcher.Discussion) error {
-	// Create RSS feed
-	rssPath := filepath.Join(g.outputDir, "rss.xml")
-	file, err := os.Create(rssPath)
-	if err != nil {
-		return fmt.Errorf("failed to create rss.xml: %w", err)
+	perPage := g.config.Build.TagPostsPerPage
+	if perPage <= 0 {
+		perPage = g.config.Build.PostsPerPage
 	}
-	defer file.Close()
 
-	// Read the RSS template from file or use default
-	rssTemplateContent, err := os.ReadFile(filepath.Join(g.templateDir, "../templates/rss.xml"))
-	if err != nil {
-		// Fallback to embedded RSS 2.0 template
-		rssTemplateContent = []byte(`<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0">
-    <channel>
-        <title>{{.Site.Title}}</title>
-        <description>{{.Site.Description}}</description>
-        <link>{{.Site.URL}}</link>
-        <lastBuildDate>{{.Updated}}</lastBuildDate>
-        <language>{{.Site.Language | default "en"}}</language>
-        <generator>Discussion Blog Generator</generator>
-        {{range .Discussions}}
-        <item>
-            <title>{{.Title}}</title>
-            <description>{{truncateHTML .Body 200}}</description>
-            <link>{{.URL}}</link>
-            <guid isPermaLink="true">{{.URL}}</guid>
-            <pubDate>{{.CreatedAt.Format "Mon, 02 Jan 2006 15:04:05 MST"}}</pubDate>
-        </item>
-        {{end}}
-    </channel>
-</rss>`)
-	}
-
-	// For RSS XML, we need to use text/template to avoid HTML escaping of XML structure
-	tmpl, err := texttemplate.New("rss").Funcs(texttemplate.FuncMap{
-		"default": func(defaultValue, value interface{}) interface{} {
-			if value == nil || value == "" {
-				return defaultValue
-			}
-			return value
-		},
-		"truncate": func(s string, length int) string {
-			if len(s) <= length {
-				return s
-			}
-			return s[:length] + "..."
-		},
-		"truncateHTML": func(s string, length int) string {
-			// Convert markdown to HTML first
-			htmlContent := blackfriday.Run([]byte(s))
-			// Remove HTML tags
-			re := regexp.MustCompile("<[^>]*>")
-			plainText := re.ReplaceAllString(string(htmlContent), "")
-			// Truncate to specified length
-			if len(plainText) > length {
-				plainText = plainText[:length] + "..."
-			}
-			
-			// Handle line endings properly for XML
-			plainText = strings.ReplaceAll(plainText, "\r\n", "\n")
-			plainText = strings.ReplaceAll(plainText, "\r", "\n")
-			
-			// Clean the string to only include valid XML 1.0 characters
-			// Valid XML 1.0 characters are: 
-			// #x9 | #xA | #xD | [#x20-#xD7FF] | [#xE000-#xFFFD] | [#x10000-#x10FFFF]
-			var cleaned strings.Builder
-			for _, r := range plainText {
-				if r == 0x09 || r == 0x0A || r == 0x0D || 
-				   (r >= 0x20 && r <= 0xD7FF) || 
-				   (r >= 0xE000 && r <= 0xFFFD) || 
-				   (r >= 0x10000 && r <= 0x10FFFF) {
-					cleaned.WriteRune(r)
-				} else {
-					// Replace invalid characters with a replacement character or skip them
-					// For XML, we'll replace with a space or a question mark
-					cleaned.WriteRune(' ')
-				}
-			}
-			plainText = cleaned.String()
-			
-			// Escape XML special characters
-			plainText = strings.ReplaceAll(plainText, "&", "&amp;")
-			plainText = strings.ReplaceAll(plainText, "<", "&lt;")
-			plainText = strings.ReplaceAll(plainText, ">", "&gt;")
-			plainText = strings.ReplaceAll(plainText, "\"", "&quot;")
-			plainText = strings.ReplaceAll(plainText, "'", "&apos;")
-			
-			// Replace newlines with XML character entities
-			plainText = strings.ReplaceAll(plainText, "\n", "&#10;")
-			
-			return plainText
-		},
-		"markdown": func(s string) string {
-			// Clean up line endings
-			s = strings.ReplaceAll(s, "\r\n", "\n")
-			s = strings.ReplaceAll(s, "\r", "\n")
-
-			// Convert markdown to HTML with Chroma
-			renderer := &ChromaRenderer{HTML: blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{
-				Flags: blackfriday.UseXHTML,
-			})}
-			extensions := blackfriday.CommonExtensions | blackfriday.AutoHeadingIDs | blackfriday.NoEmptyLineBeforeBlock
-			html := blackfriday.Run([]byte(s), blackfriday.WithRenderer(renderer), blackfriday.WithExtensions(extensions))
-			return string(html)
-		},
-		"trimBraces": func(s string) string {
-			s = strings.TrimPrefix(s, "{")
-			s = strings.TrimSuffix(s, "}")
-			return s
-		},
-	}).Parse(string(rssTemplateContent))
-	if err != nil {
-		return fmt.Errorf("failed to parse RSS template: %w", err)
-	}
-
-	// Sort discussions by creation date (newest first) and take only the latest 10
-	sortedDiscussions := make([]fetcher.Discussion, len(discussions))
-	copy(sortedDiscussions, discussions)
-	
-	// Sort by CreatedAt in descending order (newest first)
-	sort.Slice(sortedDiscussions, func(i, j int) bool {
-		return sortedDiscussions[i].CreatedAt.After(sortedDiscussions[j].CreatedAt)
-	})
-	
-	// Take only the first 10 discussions (newest ones)
-	if len(sortedDiscussions) > 10 {
-		sortedDiscussions = sortedDiscussions[:10]
-	}
-
-	// Get the most recent created time from the discussions we're including
-	var updated time.Time
-	for _, discussion := range sortedDiscussions {
-		if discussion.CreatedAt.After(updated) {
-			updated = discussion.CreatedAt
-		}
-	}
-
-	data := struct {
-		Site struct {
-			Title       string
-			Description string
-			URL         string
-			Author      string
-			Email       string
-			Language    string
-		}
-		Updated     string
-		Discussions []fetcher.Discussion
-	}{
-		Site: struct {
-			Title       string
-			Description string
-			URL         string
-			Author      string
-			Email       string
-			Language    string
+	if err := paginate(g.renderPage, tagURL, len(taggedDiscussions), perPage, func(w io.Writer, start, end int, pagination Pagination) error {
+		// Prepare data for template
+		data := struct {
+			Site        Config
+			Tag         string
+			Discussions []Post
+			Pagination  Pagination
 		}{
-			Title:       g.config.Site.Title,
-			Description: g.config.Site.Description,
-			URL:         g.config.Site.URL,
-			Author:      g.config.Site.Author,
-			Email:       g.config.Site.Email,
-			Language:    g.config.Site.Language,
-		},
-		Updated:     updated.Format("Mon, 02 Jan 2006 15:04:05 MST"),
-		Discussions: sortedDiscussions,
-	}
-
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("failed to execute RSS template: %w", err)
-	}
-
-	return nil
-}
-
-func (g *SiteGenerator) generateSearchIndex(discussions []fetcher.Discussion) error {
-	// Create search index
-	searchIndexPath := filepath.Join(g.outputDir, "search-index.json")
-	file, err := os.Create(searchIndexPath)
-	if err != nil {
-		return fmt.Errorf("failed to create search-index.json: %w", err)
-	}
-	defer file.Close()
-
-	// Convert discussions to search index format
-	var searchIndex []map[string]interface{}
-	for _, discussion := range discussions {
-		labels := make([]string, len(discussion.Labels))
-		for i, label := range discussion.Labels {
-			labels[i] = label.Name
+			Site:        g.config,
+			Tag:         tag,
+			Discussions: taggedDiscussions[start:end],
+			Pagination:  pagination,
 		}
+		g.addToSitemap(pagination.PageURL(pagination.CurrentPage), feedUpdated(taggedDiscussions[start:end]))
 
-		searchIndex = append(searchIndex, map[string]interface{}{
-			"id":       discussion.Number,
-			"title":    discussion.Title,
-			"content":  utils.PreviewContent(discussion.Body),
-			"category": discussion.Category.Name,
-			"labels":   labels,
-			"date":     discussion.CreatedAt.Format("2006-01-02"),
-		})
-	}
-
-	// Write JSON to file
-	jsonData, err := utils.ToJSON(searchIndex)
-	if err != nil {
-		return fmt.Errorf("failed to convert search index to JSON: %w", err)
-	}
-
-	if _, err := file.Write(jsonData); err != nil {
-		return fmt.Errorf("failed to write search index: %w", err)
+		// Execute the tag template
+		if err := g.templates.ExecuteTemplate(w, "tag.html", data); err != nil {
+			return fmt.Errorf("failed to execute tag template: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return err
 	}
 
-	return nil
+	// Generate the feed of the tag
+	return g.generateRSSFeed(g.feedPosts(taggedDiscussions), feedInfo{
+		Title:       fmt.Sprintf("%s - %s", g.config.Site.Title, trimBraces(tag)),
+		Description: fmt.Sprintf("Posts tagged with %s in %s", trimBraces(tag), g.config.Site.Title),
+		Link:        tagURL,
+	})
 }
 
 func (g *SiteGenerator) copyStaticAssets() error {
@@ -818,14 +678,14 @@ func (g *SiteGenerator) generateChromaCSS() error {
 	return nil
 }
 
-func (g *SiteGenerator) generateAboutPage(discussions []fetcher.Discussion) error {
+func (g *SiteGenerator) generateAboutPage(discussions []Post) error {
 	// Check if about_id is configured
 	if g.config.Site.AboutID <= 0 {
 		return nil // No about page configured
 	}
 
 	// Find the discussion with the specified ID
-	var aboutDiscussion *fetcher.Discussion
+	var aboutDiscussion *Post
 	for _, discussion := range discussions {
 		if discussion.Number == g.config.Site.AboutID {
 			aboutDiscussion = &discussion
@@ -839,7 +699,10 @@ func (g *SiteGenerator) generateAboutPage(discussions []fetcher.Discussion) erro
 	}
 
 	// Create about directory
-	aboutDir := filepath.Join(g.outputDir, "about")
+	aboutDir, err := g.outputPath("/about/")
+	if err != nil {
+		return err
+	}
 	if err := os.MkdirAll(aboutDir, 0755); err != nil {
 		return fmt.Errorf("failed to create about directory: %w", err)
 	}
@@ -853,16 +716,32 @@ func (g *SiteGenerator) generateAboutPage(discussions []fetcher.Discussion) erro
 	defer file.Close()
 
 	// Prepare data for template
+	meta := g.postMeta(*aboutDiscussion)
+	meta.Type = "website"
+	meta.URL = g.absURL("/about/")
+	meta.JSONLD = postingJSONLD("BlogPosting", meta)
+
+	content, toc := g.postContent(*aboutDiscussion)
 	data := struct {
 		Site           Config
-		Discussion     fetcher.Discussion
-		PrevDiscussion *fetcher.Discussion
-		NextDiscussion *fetcher.Discussion
+		Discussion     Post
+		Content        template.HTML
+		TOC            []*Heading
+		Related        []Post
+		Series         *SeriesNav
+		PrevDiscussion *Post
+		NextDiscussion *Post
+		Meta           Meta
 	}{
 		Site:           g.config,
 		Discussion:     *aboutDiscussion,
+		Content:        content,
+		TOC:            toc,
+		Related:        nil,
+		Series:         nil,
 		PrevDiscussion: nil,
 		NextDiscussion: nil,
+		Meta:           meta,
 	}
 
 	// Execute the post template for about page
@@ -870,5 +749,81 @@ func (g *SiteGenerator) generateAboutPage(discussions []fetcher.Discussion) erro
 		return fmt.Errorf("failed to execute post template for about page: %w", err)
 	}
 
+	if !aboutDiscussion.FrontMatter.NoIndex {
+		g.addToSitemap("/about/", postUpdated(*aboutDiscussion))
+	}
+
 	return nil
 }
+
+// renderMarkdown converts markdown to HTML, highlighting code with Chroma
+func renderMarkdown(s string) string {
+	// Convert markdown to HTML with Chroma
+	renderer := &ChromaRenderer{HTML: blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{
+		Flags: blackfriday.UseXHTML,
+	})}
+	var buf bytes.Buffer
+	renderAST(&buf, renderer, parseMarkdown(s))
+	return buf.String()
+}
+
+// excerpt renders markdown and returns its plain text, truncated to at most
+// length characters
+func excerpt(markdown string, length int) string {
+	// Convert markdown to HTML first
+	htmlContent := blackfriday.Run([]byte(markdown))
+	// Remove HTML tags
+	re := regexp.MustCompile("<[^>]*>")
+	plainText := re.ReplaceAllString(string(htmlContent), "")
+	plainText = gohtml.UnescapeString(plainText)
+	plainText = strings.Join(strings.Fields(plainText), " ")
+	// Truncate to specified length without splitting characters
+	if runes := []rune(plainText); len(runes) > length {
+		plainText = string(runes[:length]) + "..."
+	}
+	return plainText
+}
+
+// trimBraces strips the braces some label names are wrapped in
+func trimBraces(s string) string {
+	s = strings.TrimPrefix(s, "{")
+	s = strings.TrimSuffix(s, "}")
+	return s
+}
+
+// xmlEscape escapes s for use as XML character data, replacing characters
+// that are not allowed in XML 1.0
+func xmlEscape(s string) string {
+	// Handle line endings properly for XML
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+
+	// Clean the string to only include valid XML 1.0 characters
+	// Valid XML 1.0 characters are:
+	// #x9 | #xA | #xD | [#x20-#xD7FF] | [#xE000-#xFFFD] | [#x10000-#x10FFFF]
+	var cleaned strings.Builder
+	for _, r := range s {
+		if r == 0x09 || r == 0x0A || r == 0x0D ||
+			(r >= 0x20 && r <= 0xD7FF) ||
+			(r >= 0xE000 && r <= 0xFFFD) ||
+			(r >= 0x10000 && r <= 0x10FFFF) {
+			cleaned.WriteRune(r)
+		} else {
+			// Replace invalid characters with a space
+			cleaned.WriteRune(' ')
+		}
+	}
+	s = cleaned.String()
+
+	// Escape XML special characters
+	s = strings.ReplaceAll(s, "&", "&amp;")
+	s = strings.ReplaceAll(s, "<", "&lt;")
+	s = strings.ReplaceAll(s, ">", "&gt;")
+	s = strings.ReplaceAll(s, "\"", "&quot;")
+	s = strings.ReplaceAll(s, "'", "&apos;")
+
+	// Replace newlines with XML character entities
+	s = strings.ReplaceAll(s, "\n", "&#10;")
+
+	return s
+}
@@ -0,0 +1,194 @@
+package generator
+
+import (
+	"encoding/json"
+	"html/template"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"pure/entities"
+)
+
+// descriptionLength is the length of generated page descriptions in characters
+const descriptionLength = 160
+
+// markdownImageRe matches markdown and HTML images, capturing their URL
+var markdownImageRe = regexp.MustCompile(`!\[[^\]]*\]\(\s*<?([^)\s>]+)|<img[^>]+src=["']([^"']+)["']`)
+
+// Meta is the social metadata of a page, rendered by the "meta" template as
+// description, canonical link, Open Graph, Twitter Card and JSON-LD tags
+type Meta struct {
+	// Type is the Open Graph type, article or website
+	Type        string
+	SiteName    string
+	Title       string
+	Description string
+	// Image is the absolute URL of the preview image
+	Image string
+	// ImageWidth and ImageHeight are the image dimensions, if known
+	ImageWidth  int
+	ImageHeight int
+	// URL is the canonical URL of the page
+	URL       string
+	Author    string
+	Published time.Time
+	Modified  time.Time
+	Tags      []string
+	NoIndex   bool
+	// JSONLD is the structured data of the page
+	JSONLD template.JS
+}
+
+// jsonLDPerson is a schema.org Person
+type jsonLDPerson struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// jsonLDPosting is a schema.org BlogPosting or SocialMediaPosting
+type jsonLDPosting struct {
+	Context          string        `json:"@context"`
+	Type             string        `json:"@type"`
+	Headline         string        `json:"headline,omitempty"`
+	Description      string        `json:"description,omitempty"`
+	Image            string        `json:"image,omitempty"`
+	URL              string        `json:"url"`
+	MainEntityOfPage string        `json:"mainEntityOfPage"`
+	DatePublished    string        `json:"datePublished"`
+	DateModified     string        `json:"dateModified,omitempty"`
+	Author           *jsonLDPerson `json:"author,omitempty"`
+	Keywords         string        `json:"keywords,omitempty"`
+}
+
+// postingJSONLD renders meta as a schema.org posting of the given type
+func postingJSONLD(schemaType string, meta Meta) template.JS {
+	posting := jsonLDPosting{
+		Context:          "https://schema.org",
+		Type:             schemaType,
+		Headline:         meta.Title,
+		Description:      meta.Description,
+		Image:            meta.Image,
+		URL:              meta.URL,
+		MainEntityOfPage: meta.URL,
+		DatePublished:    meta.Published.Format(time.RFC3339),
+		Keywords:         strings.Join(meta.Tags, ", "),
+	}
+	if !meta.Modified.IsZero() {
+		posting.DateModified = meta.Modified.Format(time.RFC3339)
+	}
+	if meta.Author != "" {
+		posting.Author = &jsonLDPerson{Type: "Person", Name: meta.Author}
+	}
+
+	// json.Marshal escapes <, > and &, so the output can't close the script
+	output, err := json.Marshal(posting)
+	if err != nil {
+		return ""
+	}
+	return template.JS(output)
+}
+
+// postMeta computes the social metadata of a post page
+func (g *SiteGenerator) postMeta(post Post) Meta {
+	image := post.FrontMatter.Cover
+	if image == "" {
+		image = firstImage(post.Body)
+	}
+	if image != "" && strings.HasPrefix(image, "/") {
+		image = g.absURL(image)
+	}
+
+	var width, height int
+	if image == "" && post.OGImage != "" {
+		image, width, height = g.absURL(post.OGImage), ogWidth, ogHeight
+	}
+
+	meta := Meta{
+		Type:        "article",
+		SiteName:    g.config.Site.Title,
+		Title:       post.Title,
+		Description: postDescription(post),
+		Image:       image,
+		ImageWidth:  width,
+		ImageHeight: height,
+		URL:         post.Canonical(),
+		Author:      g.postAuthor(post),
+		Published:   post.PublishedAt(),
+		Modified:    postUpdated(post),
+		Tags:        postCategories(post),
+		NoIndex:     post.noIndex(),
+	}
+	if post.Answerable {
+		meta.JSONLD = qaPageJSONLD(post, meta)
+	} else {
+		meta.JSONLD = postingJSONLD("BlogPosting", meta)
+	}
+
+	return meta
+}
+
+// postDescription returns the plain text description of a post page
+func postDescription(post Post) string {
+	if post.FrontMatter.Description != "" {
+		return post.FrontMatter.Description
+	}
+	return excerpt(post.Body, descriptionLength)
+}
+
+// firstImage returns the URL of the first image in markdown
+func firstImage(markdown string) string {
+	match := markdownImageRe.FindStringSubmatch(markdown)
+	if match == nil {
+		return ""
+	}
+	if match[1] != "" {
+		return match[1]
+	}
+	return match[2]
+}
+
+// noteMeta computes the social metadata of a memo page
+func (g *NotesGenerator) noteMeta(note entities.Note) Meta {
+	title := g.noteTitle(note)
+
+	description := strings.Join(strings.Fields(note.Content), " ")
+	if utf8.RuneCountInString(description) > descriptionLength {
+		description = string([]rune(description)[:descriptionLength]) + "..."
+	}
+
+	var image string
+	var width, height int
+	if len(note.Images) > 0 {
+		image = note.Images[0]
+	} else if path, ok := g.ogImages[note.ID]; ok {
+		image = strings.TrimSuffix(g.config.URL, "/") + path
+		width, height = ogWidth, ogHeight
+	}
+
+	meta := Meta{
+		Type:        "article",
+		SiteName:    g.config.Title,
+		Title:       title,
+		Description: description,
+		Image:       image,
+		ImageWidth:  width,
+		ImageHeight: height,
+		URL:         g.notePermalink(note),
+		Author:      g.config.Author,
+		Published:   note.CreatedAt,
+		Tags:        note.Tags,
+	}
+	meta.JSONLD = postingJSONLD("SocialMediaPosting", meta)
+
+	return meta
+}
+
+// noteTitle returns the title of a memo, falling back to its ID
+func (g *NotesGenerator) noteTitle(note entities.Note) string {
+	if note.Title != "" {
+		return note.Title
+	}
+	return "Memo " + note.ID
+}
@@ -1,15 +1,22 @@
 package generator
 
 import (
+	"encoding/xml"
 	"fmt"
 	"html/template"
+	"io"
+	"mime"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"pure/entities"
+	"pure/internal/utils"
 )
 
 type NotesConfig struct {
@@ -17,6 +24,11 @@ type NotesConfig struct {
 	Description string
 	URL         string
 	Author      string
+	// PerPage is the number of memos on each page of the memos index
+	PerPage int
+	// FeedLimit is the number of newest memos included in the memos feed
+	FeedLimit int
+	OGImages  OGImages
 }
 
 type NotesGenerator struct {
@@ -24,6 +36,9 @@ type NotesGenerator struct {
 	templateDir string
 	outputDir   string
 	templates   *template.Template
+	sitemap     []sitemapURL
+	sitemapPath string
+	ogImages    map[string]string
 }
 
 func NewNotesGenerator(config NotesConfig, templateDir, outputDir string) (*NotesGenerator, error) {
@@ -50,6 +65,17 @@ func NewNotesGenerator(config NotesConfig, templateDir, outputDir string) (*Note
 		"markdown": func(s string) template.HTML {
 			return template.HTML(s)
 		},
+		"tagURL": func(s string) string {
+			return "/tags/" + utils.Slugify(trimBraces(s)) + "/"
+		},
+		"categoryURL": func(s string) string {
+			return "/category/" + utils.Slugify(s) + "/"
+		},
+		"categoryTitle": func(s string) string {
+			return s
+		},
+		"readingTime": postReadingTime,
+		"wordCount":   postWordCount,
 		// Mark string as safe HTML to prevent auto-escaping
 		"html": func(s string) template.HTML {
 			return template.HTML(s)
@@ -70,6 +96,7 @@ func NewNotesGenerator(config NotesConfig, templateDir, outputDir string) (*Note
 }
 
 func (g *NotesGenerator) Generate(notes []entities.Note) error {
+	g.sitemapPath = ""
 	if len(notes) == 0 {
 		return nil
 	}
@@ -77,6 +104,11 @@ func (g *NotesGenerator) Generate(notes []entities.Note) error {
 	sort.Slice(notes, func(i, j int) bool {
 		return notes[i].CreatedAt.After(notes[j].CreatedAt)
 	})
+	g.sitemap = nil
+
+	if err := g.generateOGImages(notes); err != nil {
+		return fmt.Errorf("failed to generate memo OG images: %w", err)
+	}
 
 	if err := g.generateNotesPage(notes); err != nil {
 		return fmt.Errorf("failed to generate notes page: %w", err)
@@ -86,40 +118,53 @@ func (g *NotesGenerator) Generate(notes []entities.Note) error {
 		return fmt.Errorf("failed to generate note pages: %w", err)
 	}
 
-	return nil
-}
-
-func (g *NotesGenerator) generateNotesPage(notes []entities.Note) error {
-	notesDir := filepath.Join(g.outputDir, "memos")
-	if err := os.MkdirAll(notesDir, 0755); err != nil {
-		return fmt.Errorf("failed to create memos directory: %w", err)
+	if err := g.generateNotesArchive(notes); err != nil {
+		return fmt.Errorf("failed to generate memos archive: %w", err)
 	}
 
-	notesPath := filepath.Join(notesDir, "index.html")
-	file, err := os.Create(notesPath)
-	if err != nil {
-		return fmt.Errorf("failed to create memos index.html: %w", err)
+	if err := g.generateNotesFeed(notes); err != nil {
+		return fmt.Errorf("failed to generate memos feed: %w", err)
 	}
-	defer file.Close()
 
-	data := struct {
-		Site  NotesConfig
-		Notes []entities.Note
-	}{
-		Site:  g.config,
-		Notes: notes,
+	if err := writeSitemap(g.outputDir, g.config.URL, "/memos/", g.sitemap); err != nil {
+		return fmt.Errorf("failed to generate memos sitemap: %w", err)
 	}
+	g.sitemapPath = "/memos/sitemap.xml"
 
-	if err := g.templates.ExecuteTemplate(file, "notes.html", data); err != nil {
-		return fmt.Errorf("failed to execute memos template: %w", err)
+	if err := g.generateSearchIndex(notes); err != nil {
+		return fmt.Errorf("failed to generate memos search index: %w", err)
 	}
 
 	return nil
 }
 
+func (g *NotesGenerator) generateNotesPage(notes []entities.Note) error {
+	return paginate(g.renderPage, "/memos/", len(notes), g.config.PerPage, func(w io.Writer, start, end int, pagination Pagination) error {
+		data := struct {
+			Site       NotesConfig
+			Notes      []entities.Note
+			Pagination Pagination
+		}{
+			Site:       g.config,
+			Notes:      notes[start:end],
+			Pagination: pagination,
+		}
+		var lastmod time.Time
+		if start < end {
+			lastmod = notes[start].CreatedAt
+		}
+		g.addToSitemap(pagination.PageURL(pagination.CurrentPage), lastmod)
+
+		if err := g.templates.ExecuteTemplate(w, "notes.html", data); err != nil {
+			return fmt.Errorf("failed to execute memos template: %w", err)
+		}
+		return nil
+	})
+}
+
 func (g *NotesGenerator) generateNotePages(notes []entities.Note) error {
 	for i, note := range notes {
-		noteDir := filepath.Join(g.outputDir, "memos", note.ID)
+		noteDir := filepath.Join(g.outputDir, filepath.FromSlash(noteURL(note)))
 		if err := os.MkdirAll(noteDir, 0755); err != nil {
 			return fmt.Errorf("failed to create note directory: %w", err)
 		}
@@ -140,30 +185,187 @@ func (g *NotesGenerator) generateNotePages(notes []entities.Note) error {
 		}
 
 		data := struct {
-			Site      NotesConfig
-			Note      entities.Note
-			PrevNote  *entities.Note
-			NextNote  *entities.Note
+			Site     NotesConfig
+			Note     entities.Note
+			PrevNote *entities.Note
+			NextNote *entities.Note
+			Meta     Meta
 		}{
-Site:     g.config,
-		Note:     note,
-		PrevNote: prevNote,
-		NextNote: nextNote,
-	}
+			Site:     g.config,
+			Note:     note,
+			Meta:     g.noteMeta(note),
+			PrevNote: prevNote,
+			NextNote: nextNote,
+		}
 
 		if err := g.templates.ExecuteTemplate(file, "note.html", data); err != nil {
 			return fmt.Errorf("failed to execute note template: %w", err)
 		}
+
+		g.addToSitemap(noteURL(note), note.CreatedAt)
 	}
 
 	return nil
 }
 
+// noteURL returns the site-relative URL of the page of a memo
+func noteURL(note entities.Note) string {
+	return "/memos/" + note.ID + "/"
+}
+
+// notePermalink returns the absolute URL of the page of a memo, which feeds,
+// meta tags and the search index link to
+func (g *NotesGenerator) notePermalink(note entities.Note) string {
+	return strings.TrimSuffix(g.config.URL, "/") + escapePath(noteURL(note))
+}
+
+// addToSitemap lists the page at the site-relative path in the memos sitemap
+func (g *NotesGenerator) addToSitemap(path string, lastmod time.Time) {
+	loc := strings.TrimSuffix(g.config.URL, "/") + escapePath(path)
+	g.sitemap = append(g.sitemap, newSitemapURL(loc, lastmod))
+}
+
+// notesRSS is the RSS 2.0 document of the memos feed
+type notesRSS struct {
+	XMLName xml.Name        `xml:"rss"`
+	Version string          `xml:"version,attr"`
+	Atom    string          `xml:"xmlns:atom,attr"`
+	Media   string          `xml:"xmlns:media,attr"`
+	Channel notesRSSChannel `xml:"channel"`
+}
+
+type notesRSSChannel struct {
+	Title         string         `xml:"title"`
+	Description   string         `xml:"description"`
+	Link          string         `xml:"link"`
+	Self          notesRSSLink   `xml:"atom:link"`
+	LastBuildDate string         `xml:"lastBuildDate"`
+	Generator     string         `xml:"generator"`
+	Items         []notesRSSItem `xml:"item"`
+}
+
+type notesRSSLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type notesRSSItem struct {
+	Title       string              `xml:"title"`
+	Description string              `xml:"description"`
+	Link        string              `xml:"link"`
+	GUID        string              `xml:"guid"`
+	Categories  []string            `xml:"category"`
+	Enclosure   *notesRSSEnclosure  `xml:"enclosure"`
+	Media       []notesMediaContent `xml:"media:content"`
+	PubDate     string              `xml:"pubDate"`
+}
+
+// notesRSSEnclosure is the single enclosure RSS 2.0 allows per item. The size
+// of Telegram images is not known without downloading them, so Length is 0.
+type notesRSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int    `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// notesMediaContent is a Media RSS element listing every image of a memo
+type notesMediaContent struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Medium string `xml:"medium,attr"`
+}
+
+// generateNotesFeed writes the RSS feed of the newest memos to /memos/rss.xml.
+// notes must be sorted newest first.
+func (g *NotesGenerator) generateNotesFeed(notes []entities.Note) error {
+	limit := g.config.FeedLimit
+	if limit <= 0 {
+		limit = defaultFeedLimit
+	}
+	if len(notes) > limit {
+		notes = notes[:limit]
+	}
+
+	baseURL := strings.TrimSuffix(g.config.URL, "/")
+	feed := notesRSS{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Media:   "http://search.yahoo.com/mrss/",
+		Channel: notesRSSChannel{
+			Title:       g.config.Title + " - Memos",
+			Description: "Memos of " + g.config.Title,
+			Link:        baseURL + "/memos/",
+			Self: notesRSSLink{
+				Href: baseURL + "/memos/rss.xml",
+				Rel:  "self",
+				Type: "application/rss+xml",
+			},
+			Generator: "Discussion Blog Generator",
+		},
+	}
+	if len(notes) > 0 {
+		feed.Channel.LastBuildDate = notes[0].CreatedAt.Format(time.RFC1123Z)
+	}
+
+	for _, note := range notes {
+		link := g.notePermalink(note)
+		item := notesRSSItem{
+			Title:       note.Title,
+			Description: note.HTML,
+			Link:        link,
+			GUID:        link,
+			Categories:  note.Tags,
+			PubDate:     note.CreatedAt.Format(time.RFC1123Z),
+		}
+		if len(note.Images) > 0 {
+			item.Enclosure = &notesRSSEnclosure{
+				URL:  note.Images[0],
+				Type: imageType(note.Images[0]),
+			}
+		}
+		for _, image := range note.Images {
+			item.Media = append(item.Media, notesMediaContent{
+				URL:    image,
+				Type:   imageType(image),
+				Medium: "image",
+			})
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+
+	output, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal memos feed: %w", err)
+	}
+
+	feedPath := filepath.Join(g.outputDir, "memos", "rss.xml")
+	if err := os.MkdirAll(filepath.Dir(feedPath), 0755); err != nil {
+		return fmt.Errorf("failed to create memos directory: %w", err)
+	}
+	if err := os.WriteFile(feedPath, append([]byte(xml.Header), output...), 0644); err != nil {
+		return fmt.Errorf("failed to write memos rss.xml: %w", err)
+	}
+
+	return nil
+}
+
+// imageType guesses the MIME type of an image from its URL
+func imageType(imageURL string) string {
+	if u, err := url.Parse(imageURL); err == nil {
+		if t := mime.TypeByExtension(path.Ext(u.Path)); strings.HasPrefix(t, "image/") {
+			return t
+		}
+	}
+	return "image/jpeg"
+}
+
 func notesTruncateHTML(s string, length int) string {
 	re := regexp.MustCompile("<[^>]*>")
 	plain := re.ReplaceAllString(s, "")
-	if len(plain) > length {
-		plain = plain[:length] + "..."
+	// Truncate without splitting characters
+	if runes := []rune(plain); len(runes) > length {
+		plain = string(runes[:length]) + "..."
 	}
 	return plain
 }
\ No newline at end of file
@@ -0,0 +1,570 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"pure/entities"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// Open Graph card dimensions recommended by Facebook, X and Telegram
+const (
+	ogWidth  = 1200
+	ogHeight = 630
+)
+
+// ogLayoutVersion is part of the cache key of every card. Bump it whenever
+// the card layout changes so cached cards get redrawn.
+const ogLayoutVersion = 1
+
+const (
+	ogPadding       = 80
+	ogMaxTitleLines = 4
+)
+
+var (
+	ogBackground = color.RGBA{0xfa, 0xfa, 0xf9, 0xff}
+	ogForeground = color.RGBA{0x18, 0x18, 0x1b, 0xff}
+	ogMuted      = color.RGBA{0x71, 0x71, 0x7a, 0xff}
+)
+
+// OGImages configures the Open Graph card images generated for pages
+// without an image of their own
+type OGImages struct {
+	// Enabled turns card generation on
+	Enabled bool
+	// Font is the path of a TrueType or OpenType font (or collection) used
+	// for the text. It should cover CJK characters; glyphs it lacks fall back
+	// to the built-in Go fonts, which only cover Latin, Greek and Cyrillic.
+	// Cards with text no font covers are skipped rather than drawn with
+	// missing glyphs.
+	Font string
+	// FontIndex selects the font of a collection, such as 2 for the
+	// Simplified Chinese face of NotoSansCJK-Bold.ttc
+	FontIndex int
+	// Cache is the directory cards are kept in between builds, so that they
+	// are only redrawn when their content changes
+	Cache string
+	// Strict fails the build when the font is missing or a card cannot be
+	// drawn, instead of skipping the card with a warning
+	Strict bool
+}
+
+// ogCard is the content drawn on a card
+type ogCard struct {
+	SiteTitle string
+	Title     string
+	Date      time.Time
+	Tags      []string
+}
+
+// generateOGImages draws a card for every post without an image of its own
+func (g *SiteGenerator) generateOGImages(discussions []Post) error {
+	if !g.config.Build.OGImages.Enabled {
+		return nil
+	}
+
+	renderer, err := newOGRenderer(g.config.Build.OGImages, g.outputDir)
+	if err != nil {
+		return err
+	}
+
+	for i := range discussions {
+		post := &discussions[i]
+		if post.FrontMatter.Cover != "" || firstImage(post.Body) != "" {
+			continue
+		}
+
+		card := ogCard{
+			SiteTitle: g.config.Site.Title,
+			Title:     post.Title,
+			Date:      post.PublishedAt(),
+			Tags:      postCategories(*post),
+		}
+		name := fmt.Sprintf("discussion #%d %q", post.Number, post.Title)
+		if post.OGImage, err = renderer.render(fmt.Sprint(post.Number), name, card); err != nil {
+			return fmt.Errorf("failed to render OG image of discussion #%d: %w", post.Number, err)
+		}
+	}
+
+	return renderer.save()
+}
+
+// generateOGImages draws a card for every memo without images
+func (g *NotesGenerator) generateOGImages(notes []entities.Note) error {
+	g.ogImages = make(map[string]string)
+	if !g.config.OGImages.Enabled {
+		return nil
+	}
+
+	renderer, err := newOGRenderer(g.config.OGImages, g.outputDir)
+	if err != nil {
+		return err
+	}
+
+	for _, note := range notes {
+		if len(note.Images) > 0 {
+			continue
+		}
+
+		card := ogCard{
+			SiteTitle: g.config.Title + " · Memos",
+			Title:     g.noteTitle(note),
+			Date:      note.CreatedAt,
+			Tags:      note.Tags,
+		}
+		path, err := renderer.render("memo-"+note.ID, "memo "+note.ID, card)
+		if err != nil {
+			return fmt.Errorf("failed to render OG image of memo %s: %w", note.ID, err)
+		}
+		if path != "" {
+			g.ogImages[note.ID] = path
+		}
+	}
+
+	return renderer.save()
+}
+
+// ogManifestName is the name of the file in the cache directory that maps
+// card IDs to the hash of the content they were drawn from
+const ogManifestName = "manifest.json"
+
+// ogRenderer draws cards into /og/ of the output directory
+type ogRenderer struct {
+	config    OGImages
+	outputDir string
+	fonts     []*sfnt.Font
+	bold      []*sfnt.Font
+	// fontHash is the hash of the configured font file, so that replacing
+	// the file redraws the cards
+	fontHash string
+	manifest map[string]string
+	// skipped counts the cards left out for lack of glyphs
+	skipped int
+	buf     sfnt.Buffer
+}
+
+// newOGRenderer loads the fonts and the cache manifest
+func newOGRenderer(config OGImages, outputDir string) (*ogRenderer, error) {
+	r := &ogRenderer{
+		config:    config,
+		outputDir: outputDir,
+		manifest:  make(map[string]string),
+	}
+
+	regular, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse built-in font: %w", err)
+	}
+	bold, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse built-in font: %w", err)
+	}
+
+	if config.Font != "" {
+		custom, hash, err := loadFont(config.Font, config.FontIndex)
+		switch {
+		case err != nil && config.Strict:
+			return nil, err
+		case err != nil:
+			fmt.Printf("Warning: drawing OG images with the built-in fonts only: %v\n", err)
+		default:
+			r.fontHash = hash
+			r.fonts = append(r.fonts, custom)
+			r.bold = append(r.bold, custom)
+		}
+	}
+	r.fonts = append(r.fonts, regular)
+	r.bold = append(r.bold, bold)
+
+	if config.Cache != "" {
+		data, err := os.ReadFile(filepath.Join(config.Cache, ogManifestName))
+		if err == nil {
+			if err := json.Unmarshal(data, &r.manifest); err != nil {
+				fmt.Printf("Warning: ignoring corrupt OG image manifest: %v\n", err)
+				r.manifest = make(map[string]string)
+			}
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read OG image manifest: %w", err)
+		}
+	}
+
+	return r, nil
+}
+
+// loadFont parses a TrueType or OpenType font file, taking the font at index
+// of a collection, and returns it with the hash of the file
+func loadFont(path string, index int) (*sfnt.Font, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read font: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	hash := fmt.Sprintf("%s-%d", hex.EncodeToString(sum[:]), index)
+
+	if f, err := opentype.Parse(data); err == nil {
+		return f, hash, nil
+	}
+
+	collection, err := opentype.ParseCollection(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse font %s: %w", path, err)
+	}
+	f, err := collection.Font(index)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse font %s: %w", path, err)
+	}
+	return f, hash, nil
+}
+
+// missingGlyphs returns the characters of the card that no font has a glyph
+// for, each listed once
+func (r *ogRenderer) missingGlyphs(card ogCard) string {
+	var missing []rune
+	text := card.SiteTitle + card.Title + strings.Join(card.Tags, "")
+	for _, c := range text {
+		if unicode.IsSpace(c) || unicode.IsControl(c) || strings.ContainsRune(string(missing), c) {
+			continue
+		}
+		found := false
+		for _, f := range r.fonts {
+			if index, err := f.GlyphIndex(&r.buf, c); err == nil && index != 0 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, c)
+		}
+	}
+	return string(missing)
+}
+
+// render writes the card with the given ID to /og/<id>.png and returns its
+// site-relative URL. Cached cards are reused when their content is unchanged.
+// Cards the fonts cannot draw are skipped with a warning naming the page, and
+// get an empty URL; in strict mode they fail the build.
+func (r *ogRenderer) render(id, name string, card ogCard) (string, error) {
+	if missing := r.missingGlyphs(card); missing != "" {
+		if r.config.Strict {
+			return "", fmt.Errorf("fonts have no glyphs for %q, set build.ogImages.font to a font covering them", missing)
+		}
+		fmt.Printf("Warning: skipping OG image of %s, the fonts have no glyphs for %q\n", name, missing)
+		r.skipped++
+		return "", nil
+	}
+
+	urlPath := "/og/" + id + ".png"
+	outputPath := filepath.Join(r.outputDir, "og", id+".png")
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create og directory: %w", err)
+	}
+
+	key, err := r.cacheKey(card)
+	if err != nil {
+		return "", err
+	}
+
+	if r.config.Cache != "" {
+		cachePath := filepath.Join(r.config.Cache, id+".png")
+		if r.manifest[id] == key {
+			if data, err := os.ReadFile(cachePath); err == nil {
+				if err := os.WriteFile(outputPath, data, 0644); err != nil {
+					return "", fmt.Errorf("failed to write OG image: %w", err)
+				}
+				return urlPath, nil
+			}
+		}
+
+		if err := os.MkdirAll(r.config.Cache, 0755); err != nil {
+			return "", fmt.Errorf("failed to create OG image cache: %w", err)
+		}
+		if err := r.drawTo(cachePath, card); err != nil {
+			return "", err
+		}
+		r.manifest[id] = key
+
+		data, err := os.ReadFile(cachePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read cached OG image: %w", err)
+		}
+		if err := os.WriteFile(outputPath, data, 0644); err != nil {
+			return "", fmt.Errorf("failed to write OG image: %w", err)
+		}
+		return urlPath, nil
+	}
+
+	if err := r.drawTo(outputPath, card); err != nil {
+		return "", err
+	}
+	return urlPath, nil
+}
+
+// cacheKey hashes everything a card's pixels depend on
+func (r *ogRenderer) cacheKey(card ogCard) (string, error) {
+	data, err := json.Marshal(struct {
+		Version int
+		Font    string
+		Card    ogCard
+	}{ogLayoutVersion, r.fontHash, card})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash OG image: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// save writes the cache manifest. Entries of other generators sharing the
+// cache are kept.
+func (r *ogRenderer) save() error {
+	if r.skipped > 0 {
+		fmt.Printf("Warning: skipped %d OG images, set build.ogImages.font to a font covering their text, such as Noto Sans CJK\n", r.skipped)
+	}
+	if r.config.Cache == "" {
+		return nil
+	}
+
+	merged := make(map[string]string)
+	if data, err := os.ReadFile(filepath.Join(r.config.Cache, ogManifestName)); err == nil {
+		json.Unmarshal(data, &merged)
+	}
+	for id, key := range r.manifest {
+		merged[id] = key
+	}
+
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal OG image manifest: %w", err)
+	}
+	if err := os.MkdirAll(r.config.Cache, 0755); err != nil {
+		return fmt.Errorf("failed to create OG image cache: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(r.config.Cache, ogManifestName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write OG image manifest: %w", err)
+	}
+
+	return nil
+}
+
+// drawTo draws a card into a PNG file
+func (r *ogRenderer) drawTo(path string, card ogCard) error {
+	img, err := r.draw(card)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create OG image: %w", err)
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, img); err != nil {
+		return fmt.Errorf("failed to encode OG image: %w", err)
+	}
+
+	return nil
+}
+
+// draw lays out a card: the site title at the top, the wrapped post title
+// below it, and the date and tags at the bottom
+func (r *ogRenderer) draw(card ogCard) (image.Image, error) {
+	img := image.NewRGBA(image.Rect(0, 0, ogWidth, ogHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(ogBackground), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, ogWidth, 12), image.NewUniform(ogForeground), image.Point{}, draw.Src)
+
+	small, err := newFaceChain(r.fonts, 30)
+	if err != nil {
+		return nil, err
+	}
+	small.draw(img, ogPadding, 120, card.SiteTitle, ogMuted)
+
+	// Shrink the title until it fits
+	maxWidth := ogWidth - 2*ogPadding
+	var title *faceChain
+	var lines []string
+	for _, size := range []float64{72, 64, 56, 48} {
+		if title, err = newFaceChain(r.bold, size); err != nil {
+			return nil, err
+		}
+		lines = title.wrap(card.Title, maxWidth)
+		if len(lines) <= ogMaxTitleLines {
+			break
+		}
+	}
+	if len(lines) > ogMaxTitleLines {
+		lines = lines[:ogMaxTitleLines]
+		lines[ogMaxTitleLines-1] = title.ellipsize(lines[ogMaxTitleLines-1], maxWidth)
+	}
+
+	lineHeight := title.lineHeight() * 5 / 4
+	y := 200 + title.ascent()
+	for _, line := range lines {
+		title.draw(img, ogPadding, y, line, ogForeground)
+		y += lineHeight
+	}
+
+	footer := card.Date.Format("January 2, 2006")
+	for _, tag := range card.Tags {
+		footer += "  #" + tag
+	}
+	small.draw(img, ogPadding, ogHeight-ogPadding, small.ellipsize(footer, maxWidth), ogMuted)
+
+	return img, nil
+}
+
+// faceChain draws text with the first of several font faces that has a
+// glyph for each character
+type faceChain struct {
+	fonts []*sfnt.Font
+	faces []font.Face
+	buf   sfnt.Buffer
+}
+
+func newFaceChain(fonts []*sfnt.Font, size float64) (*faceChain, error) {
+	chain := &faceChain{fonts: fonts}
+	for _, f := range fonts {
+		face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create font face: %w", err)
+		}
+		chain.faces = append(chain.faces, face)
+	}
+	return chain, nil
+}
+
+// face returns the face used for r
+func (c *faceChain) face(r rune) font.Face {
+	for i, f := range c.fonts {
+		if index, err := f.GlyphIndex(&c.buf, r); err == nil && index != 0 {
+			return c.faces[i]
+		}
+	}
+	return c.faces[len(c.faces)-1]
+}
+
+func (c *faceChain) ascent() int {
+	return c.faces[0].Metrics().Ascent.Ceil()
+}
+
+func (c *faceChain) lineHeight() int {
+	return c.faces[0].Metrics().Height.Ceil()
+}
+
+// width measures s in pixels
+func (c *faceChain) width(s string) int {
+	var width fixed.Int26_6
+	for _, r := range s {
+		advance, _ := c.face(r).GlyphAdvance(r)
+		width += advance
+	}
+	return width.Ceil()
+}
+
+// draw draws s with its baseline starting at x, y
+func (c *faceChain) draw(dst draw.Image, x, y int, s string, col color.Color) {
+	drawer := font.Drawer{Dst: dst, Src: image.NewUniform(col), Dot: fixed.P(x, y)}
+	for _, r := range s {
+		drawer.Face = c.face(r)
+		drawer.DrawString(string(r))
+	}
+}
+
+// wrap breaks s into lines no wider than maxWidth. Latin words are kept
+// whole, CJK text may break between any two characters.
+func (c *faceChain) wrap(s string, maxWidth int) []string {
+	var lines []string
+	var line string
+	for _, token := range wrapTokens(s) {
+		candidate := line + token
+		if line == "" {
+			candidate = strings.TrimLeft(token, " ")
+		}
+		if line != "" && c.width(candidate) > maxWidth {
+			lines = append(lines, strings.TrimRight(line, " "))
+			candidate = strings.TrimLeft(token, " ")
+		}
+		// A single word wider than the card is broken by character
+		for c.width(candidate) > maxWidth {
+			runes := []rune(candidate)
+			n := len(runes) - 1
+			for n > 1 && c.width(string(runes[:n])) > maxWidth {
+				n--
+			}
+			lines = append(lines, string(runes[:n]))
+			candidate = string(runes[n:])
+		}
+		line = candidate
+	}
+	if strings.TrimSpace(line) != "" {
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	return lines
+}
+
+// ellipsize shortens s with an ellipsis so that it fits maxWidth
+func (c *faceChain) ellipsize(s string, maxWidth int) string {
+	if c.width(s) <= maxWidth {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && c.width(string(runes)+"…") > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimRight(string(runes), " ") + "…"
+}
+
+// wrapTokens splits s into the units line wrapping may not break: words with
+// their leading spaces, and single CJK characters
+func wrapTokens(s string) []string {
+	var tokens []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range strings.Join(strings.Fields(s), " ") {
+		switch {
+		case isWideRune(r):
+			flush()
+			tokens = append(tokens, string(r))
+		case r == ' ':
+			flush()
+			current.WriteRune(r)
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// isWideRune reports whether line breaks are allowed around r, as they are
+// around Chinese, Japanese and Korean characters
+func isWideRune(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		(r >= 0x3000 && r <= 0x303f) || (r >= 0xff00 && r <= 0xffef)
+}
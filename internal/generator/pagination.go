@@ -0,0 +1,104 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultPerPage is the page size used when none is configured
+const defaultPerPage = 10
+
+// Pagination describes the position of a page within a paginated listing
+type Pagination struct {
+	CurrentPage int
+	TotalPages  int
+	HasPrev     bool
+	HasNext     bool
+	PrevPage    int
+	NextPage    int
+	// BaseURL is the URL of the first page, such as / or /tags/go/
+	BaseURL string
+}
+
+// PageURL returns the URL of page n. The first page lives at BaseURL, the
+// others at BaseURL/page/n/.
+func (p Pagination) PageURL(n int) string {
+	if n <= 1 {
+		return p.BaseURL
+	}
+	return fmt.Sprintf("%spage/%d/", p.BaseURL, n)
+}
+
+// PrevURL returns the URL of the previous page
+func (p Pagination) PrevURL() string {
+	return p.PageURL(p.PrevPage)
+}
+
+// NextURL returns the URL of the next page
+func (p Pagination) NextURL() string {
+	return p.PageURL(p.NextPage)
+}
+
+// paginate splits total items into pages of perPage items below baseURL. For
+// every page it creates the page's index.html with write and calls render
+// with it, the range of items on the page and the page's pagination. An empty
+// listing still gets a first page.
+func paginate(write func(urlPath string, render func(w io.Writer) error) error, baseURL string, total, perPage int, render func(w io.Writer, start, end int, pagination Pagination) error) error {
+	if perPage <= 0 {
+		perPage = defaultPerPage
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+
+	totalPages := (total + perPage - 1) / perPage
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	for page := 1; page <= totalPages; page++ {
+		pagination := Pagination{
+			CurrentPage: page,
+			TotalPages:  totalPages,
+			HasPrev:     page > 1,
+			HasNext:     page < totalPages,
+			PrevPage:    page - 1,
+			NextPage:    page + 1,
+			BaseURL:     baseURL,
+		}
+
+		start := (page - 1) * perPage
+		end := start + perPage
+		if end > total {
+			end = total
+		}
+
+		if err := write(pagination.PageURL(page), func(w io.Writer) error {
+			return render(w, start, end, pagination)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renderPage creates the index.html of the page at urlPath inside outputDir
+// and calls render with it
+func renderPage(outputDir, urlPath string, render func(w io.Writer) error) error {
+	pageDir := filepath.Join(outputDir, filepath.FromSlash(urlPath))
+	if err := os.MkdirAll(pageDir, 0755); err != nil {
+		return fmt.Errorf("failed to create page directory: %w", err)
+	}
+
+	file, err := os.Create(filepath.Join(pageDir, "index.html"))
+	if err != nil {
+		return fmt.Errorf("failed to create index.html: %w", err)
+	}
+	defer file.Close()
+
+	return render(file)
+}
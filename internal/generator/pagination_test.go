@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		total   int
+		perPage int
+		// want lists every page as "url [start,end) page/total"
+		want []string
+	}{
+		{
+			name:    "no items",
+			baseURL: "/tags/go/",
+			total:   0,
+			perPage: 10,
+			want:    []string{"/tags/go/ [0,0) 1/1"},
+		},
+		{
+			name:    "exactly one page",
+			baseURL: "/",
+			total:   10,
+			perPage: 10,
+			want:    []string{"/ [0,10) 1/1"},
+		},
+		{
+			name:    "partial last page",
+			baseURL: "/memos",
+			total:   5,
+			perPage: 2,
+			want: []string{
+				"/memos/ [0,2) 1/3",
+				"/memos/page/2/ [2,4) 2/3",
+				"/memos/page/3/ [4,5) 3/3",
+			},
+		},
+		{
+			name:    "unset page size",
+			baseURL: "/",
+			total:   11,
+			perPage: 0,
+			want:    []string{"/ [0,10) 1/2", "/page/2/ [10,11) 2/2"},
+		},
+		{
+			name:    "negative page size",
+			baseURL: "/",
+			total:   3,
+			perPage: -1,
+			want:    []string{"/ [0,3) 1/1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			var urls []string
+			write := func(urlPath string, render func(w io.Writer) error) error {
+				urls = append(urls, urlPath)
+				return render(io.Discard)
+			}
+			err := paginate(write, tt.baseURL, tt.total, tt.perPage, func(w io.Writer, start, end int, p Pagination) error {
+				got = append(got, fmt.Sprintf("%s [%d,%d) %d/%d", urls[len(urls)-1], start, end, p.CurrentPage, p.TotalPages))
+
+				if p.HasPrev != (p.CurrentPage > 1) || p.HasNext != (p.CurrentPage < p.TotalPages) {
+					t.Errorf("page %d: HasPrev = %v, HasNext = %v", p.CurrentPage, p.HasPrev, p.HasNext)
+				}
+				if p.HasPrev && p.PrevURL() != urls[len(urls)-2] {
+					t.Errorf("page %d: PrevURL() = %q, want %q", p.CurrentPage, p.PrevURL(), urls[len(urls)-2])
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("paginate() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pages = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
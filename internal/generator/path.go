@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// sections are the top-level directories the generators write to. Post slugs
+// must not take them when posts live at /:slug/.
+var sections = []string{
+	"about", "archive", "category", "faq", "js", "memos", "og", "page",
+	"post", "posts", "search", "series", "styles", "tags",
+}
+
+// outputPath resolves the site-relative URL path of a page about to be
+// written to a path inside the output directory, and claims it so that no
+// other page is written there during this build. See checkPath for the paths
+// that are rejected.
+func (g *SiteGenerator) outputPath(urlPath string) (string, error) {
+	path, err := g.checkPath(urlPath)
+	if err != nil {
+		return "", err
+	}
+	if g.pages == nil {
+		g.pages = make(map[string]bool)
+	}
+	if g.pages[path] {
+		return "", fmt.Errorf("path %q is already taken by another page", urlPath)
+	}
+	g.pages[path] = true
+	return path, nil
+}
+
+// written reports whether a page has been written at urlPath during this build
+func (g *SiteGenerator) written(urlPath string) bool {
+	path, err := g.checkPath(urlPath)
+	return err == nil && g.pages[path]
+}
+
+// checkPath resolves a site-relative URL path to a path inside the output
+// directory. Paths with parent segments or characters that are illegal on
+// some filesystems are rejected rather than cleaned up, since they would
+// otherwise silently write somewhere else than the URL points to.
+func (g *SiteGenerator) checkPath(urlPath string) (string, error) {
+	for _, segment := range strings.Split(urlPath, "/") {
+		if segment == "." || segment == ".." {
+			return "", fmt.Errorf("path %q must not contain %q segments", urlPath, segment)
+		}
+		if strings.ContainsAny(segment, "\\:*?\"<>|\x00") {
+			return "", fmt.Errorf("path %q contains characters that are not allowed in file names", urlPath)
+		}
+	}
+
+	path := filepath.Join(g.outputDir, filepath.FromSlash(urlPath))
+	rel, err := filepath.Rel(g.outputDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %q escapes the output directory", urlPath)
+	}
+
+	return path, nil
+}
+
+// renderPage claims the page at urlPath and renders it
+func (g *SiteGenerator) renderPage(urlPath string, render func(w io.Writer) error) error {
+	if _, err := g.outputPath(urlPath); err != nil {
+		return err
+	}
+	return renderPage(g.outputDir, urlPath, render)
+}
+
+// renderPage renders the memo page at urlPath
+func (g *NotesGenerator) renderPage(urlPath string, render func(w io.Writer) error) error {
+	return renderPage(g.outputDir, urlPath, render)
+}
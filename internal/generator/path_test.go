@@ -0,0 +1,54 @@
+package generator
+
+import (
+	"testing"
+
+	"pure/internal/fetcher"
+)
+
+func TestOutputPathRefusesSecondWrite(t *testing.T) {
+	g := &SiteGenerator{outputDir: t.TempDir()}
+	if _, err := g.outputPath("/hello/"); err != nil {
+		t.Fatalf("first outputPath() error = %v", err)
+	}
+	for _, urlPath := range []string{"/hello/", "/hello", "hello/"} {
+		if _, err := g.outputPath(urlPath); err == nil {
+			t.Errorf("outputPath(%q) succeeded, want an error for a page written twice", urlPath)
+		}
+	}
+	if !g.written("/hello/") || g.written("/other/") {
+		t.Errorf("written() does not match the claimed pages")
+	}
+	for _, urlPath := range []string{"/../etc/", "/a:b/"} {
+		if _, err := g.outputPath(urlPath); err == nil {
+			t.Errorf("outputPath(%q) succeeded, want an error", urlPath)
+		}
+	}
+}
+
+func TestAssignSlugsReservesSections(t *testing.T) {
+	titles := []string{"About", "Tags", "Memos", "Search", "Category", "Page", "Hello"}
+
+	tests := []struct {
+		permalink string
+		want      []string
+	}{
+		{"/:slug/", []string{"about-2", "tags-2", "memos-2", "search-2", "category-2", "page-2", "hello"}},
+		{":slug/", []string{"about-2", "tags-2", "memos-2", "search-2", "category-2", "page-2", "hello"}},
+		// Below a year, slugs can't clash with a section
+		{"/:year/:slug/", []string{"about", "tags", "memos", "search", "category", "page", "hello"}},
+	}
+	for _, tt := range tests {
+		g := &SiteGenerator{config: Config{Build: Build{Permalink: tt.permalink}}}
+		posts := make([]Post, len(titles))
+		for i, title := range titles {
+			posts[i] = Post{Discussion: fetcher.Discussion{Number: i + 1, Title: title}}
+		}
+		g.assignSlugs(posts)
+		for i, post := range posts {
+			if post.Slug != tt.want[i] {
+				t.Errorf("%s: slug of %q = %q, want %q", tt.permalink, post.Title, post.Slug, tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package generator
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"pure/internal/fetcher"
+	"pure/internal/utils"
+)
+
+// defaultPermalink is the permalink pattern used when none is configured
+const defaultPermalink = "/post/:number/"
+
+// Post is a discussion together with the data the generator derives from it
+type Post struct {
+	fetcher.Discussion
+
+	// Slug is the unique URL name of the post
+	Slug string
+	// Path is the site-relative URL of the post page, such as /2024/01/hello/
+	Path string
+	// Permalink is the absolute URL of the post page on the site, which
+	// feeds, meta tags and the search index link to
+	Permalink string
+	// OGImage is the site-relative URL of the generated Open Graph card
+	OGImage string
+	// WordCount is the number of CJK characters plus Latin words in the
+	// post, not counting code blocks
+	WordCount int
+	// ReadingTime is the estimated reading time in minutes
+	ReadingTime int
+	// Language is the language reading time and word count are shown in
+	Language string
+	// Draft, Unlisted and Scheduled are the publication states of the post.
+	// Drafts and scheduled posts are only built when previewing drafts.
+	Draft     bool
+	Unlisted  bool
+	Scheduled bool
+	// SeriesName is the series the post is a part of, if any
+	SeriesName string
+	// Featured posts are shown above the first page of the index
+	Featured bool
+}
+
+// Canonical returns the canonical URL of the post: the front matter
+// canonical if set, otherwise its permalink
+func (p Post) Canonical() string {
+	if p.FrontMatter.Canonical != "" {
+		return p.FrontMatter.Canonical
+	}
+	return p.Permalink
+}
+
+// preparePosts derives the generated data of every discussion
+func (g *SiteGenerator) preparePosts(discussions []fetcher.Discussion) []Post {
+	posts := make([]Post, len(discussions))
+	for i, discussion := range discussions {
+		posts[i] = Post{
+			Discussion: discussion,
+			Draft:      g.isDraft(discussion),
+			Unlisted:   g.isUnlisted(discussion),
+			Scheduled:  g.isScheduled(discussion),
+			Featured:   g.isFeatured(discussion),
+		}
+		posts[i].SeriesName = seriesName(posts[i])
+		g.stripStateLabels(&posts[i])
+		stripSeriesLabels(&posts[i])
+	}
+
+	g.assignSlugs(posts)
+	for i := range posts {
+		posts[i].Path = g.expandPermalink(g.permalinkPattern(), posts[i])
+		posts[i].Permalink = g.absURL(posts[i].Path)
+
+		cjk, latin := countWords(posts[i].Body)
+		posts[i].WordCount = cjk + latin
+		posts[i].ReadingTime = g.readingMinutes(cjk, latin)
+		posts[i].Language = g.postLanguage(cjk, latin)
+	}
+
+	return posts
+}
+
+// assignSlugs gives every post a unique slug. Slugs set in front matter are
+// reserved first, the rest are derived from titles in creation order so that
+// older posts keep their URL when a newer post has the same title.
+func (g *SiteGenerator) assignSlugs(posts []Post) {
+	order := make([]*Post, len(posts))
+	for i := range posts {
+		order[i] = &posts[i]
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].Number < order[j].Number
+	})
+
+	slugger := utils.NewSlugger(g.config.Build.Pinyin)
+	// Posts at /:slug/ would overwrite the index of a section of the same name
+	if first, _, _ := strings.Cut(strings.TrimPrefix(g.permalinkPattern(), "/"), "/"); first == ":slug" {
+		for _, section := range sections {
+			slugger.Reserve(section)
+		}
+	}
+	for _, post := range order {
+		if slug := utils.Slugify(post.FrontMatter.Slug); slug != "" {
+			post.Slug = slugger.Reserve(slug)
+		}
+	}
+	for _, post := range order {
+		if post.Slug == "" {
+			post.Slug = slugger.Slug(post.Title, strconv.Itoa(post.Number))
+		}
+	}
+}
+
+// permalinkPattern returns the configured permalink pattern
+func (g *SiteGenerator) permalinkPattern() string {
+	if g.config.Build.Permalink == "" {
+		return defaultPermalink
+	}
+	return g.config.Build.Permalink
+}
+
+// expandPermalink fills in the placeholders of a permalink pattern:
+// :year, :month, :day, :slug and :number
+func (g *SiteGenerator) expandPermalink(pattern string, post Post) string {
+	date := post.PublishedAt()
+	replacer := strings.NewReplacer(
+		":year", fmt.Sprintf("%04d", date.Year()),
+		":month", fmt.Sprintf("%02d", int(date.Month())),
+		":day", fmt.Sprintf("%02d", date.Day()),
+		":slug", post.Slug,
+		":number", strconv.Itoa(post.Number),
+	)
+
+	path := replacer.Replace(pattern)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+	return path
+}
+
+// publicNames collects the names of the posts, such as their labels, in the
+// order slugs are assigned in: sorted, with names only used by unlisted,
+// draft or scheduled posts last, so that hidden posts never change the slugs
+// of public ones
+func publicNames(posts []Post, namesOf func(Post) []string) []string {
+	var public, hidden []string
+	seen := make(map[string]bool)
+	// Public posts go first, so that their names are never taken as hidden
+	for _, wantHidden := range []bool{false, true} {
+		for _, post := range posts {
+			if isHidden := post.Unlisted || post.Draft || post.Scheduled; isHidden != wantHidden {
+				continue
+			}
+			for _, name := range namesOf(post) {
+				if name == "" || seen[name] {
+					continue
+				}
+				seen[name] = true
+				if wantHidden {
+					hidden = append(hidden, name)
+				} else {
+					public = append(public, name)
+				}
+			}
+		}
+	}
+	sort.Strings(public)
+	sort.Strings(hidden)
+	return append(public, hidden...)
+}
+
+// prepareTags assigns every label a unique slug used for its tag page
+func (g *SiteGenerator) prepareTags(posts []Post) error {
+	names := publicNames(posts, func(post Post) []string {
+		var names []string
+		for _, label := range post.Labels {
+			names = append(names, label.Name)
+		}
+		return names
+	})
+
+	slugger := utils.NewSlugger(g.config.Build.Pinyin)
+	g.tagSlugs = make(map[string]string, len(names))
+	for _, name := range names {
+		g.tagSlugs[name] = slugger.Slug(trimBraces(name), "tag")
+		if _, err := g.checkPath(g.tagURL(name)); err != nil {
+			return fmt.Errorf("invalid tag %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// tagURL returns the site-relative URL of the tag page of a label
+func (g *SiteGenerator) tagURL(name string) string {
+	slug, ok := g.tagSlugs[name]
+	if !ok {
+		slug = utils.Slugify(trimBraces(name))
+	}
+	return "/tags/" + slug + "/"
+}
+
+// prepareCategories assigns every discussion category a unique slug used for
+// its pages below /category/
+func (g *SiteGenerator) prepareCategories(posts []Post) error {
+	names := publicNames(posts, func(post Post) []string {
+		return []string{post.Category.Name}
+	})
+
+	// Slugs set in the category configuration are reserved first
+	slugger := utils.NewSlugger(g.config.Build.Pinyin)
+	g.categorySlugs = make(map[string]string, len(names))
+	for _, name := range names {
+		if slug := utils.Slugify(g.categoryConfig(name).Slug); slug != "" {
+			g.categorySlugs[name] = slugger.Reserve(slug)
+		}
+	}
+	for _, name := range names {
+		if _, ok := g.categorySlugs[name]; !ok {
+			g.categorySlugs[name] = slugger.Slug(name, "category")
+		}
+		if _, err := g.checkPath(g.categoryURL(name)); err != nil {
+			return fmt.Errorf("invalid category %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// categoryURL returns the site-relative URL of the pages of a category
+func (g *SiteGenerator) categoryURL(name string) string {
+	slug, ok := g.categorySlugs[name]
+	if !ok {
+		slug = utils.Slugify(name)
+	}
+	return "/category/" + slug + "/"
+}
+
+// absURL turns a site-relative path into an absolute, escaped URL
+func (g *SiteGenerator) absURL(path string) string {
+	return strings.TrimSuffix(g.config.Site.URL, "/") + escapePath(path)
+}
+
+// escapePath percent-encodes a URL path
+func escapePath(path string) string {
+	u := url.URL{Path: path}
+	return u.EscapedPath()
+}
@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"testing"
+
+	"pure/internal/fetcher"
+)
+
+// labeledPost returns a post with the given labels
+func labeledPost(number int, labels ...string) Post {
+	post := Post{Discussion: fetcher.Discussion{Number: number}}
+	for _, label := range labels {
+		post.Labels = append(post.Labels, struct{ Name string }{label})
+	}
+	return post
+}
+
+func TestPrepareTagsIgnoresHiddenPosts(t *testing.T) {
+	public := []Post{labeledPost(1, "go", "Rust"), labeledPost(2, "rust")}
+
+	hidden := []Post{labeledPost(3, "GO", "Draft"), labeledPost(4, "Go"), labeledPost(5, "RUST")}
+	hidden[0].Unlisted = true
+	hidden[1].Draft = true
+	hidden[2].Scheduled = true
+
+	without := &SiteGenerator{outputDir: t.TempDir()}
+	if err := without.prepareTags(public); err != nil {
+		t.Fatal(err)
+	}
+	with := &SiteGenerator{outputDir: t.TempDir()}
+	if err := with.prepareTags(append(hidden, public...)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"go", "Rust", "rust"} {
+		if a, b := without.tagURL(name), with.tagURL(name); a != b {
+			t.Errorf("tagURL(%q) = %q with hidden posts, want %q", name, b, a)
+		}
+	}
+	if got := with.tagURL("go"); got != "/tags/go/" {
+		t.Errorf("tagURL(go) = %q, want /tags/go/", got)
+	}
+	// Hidden names still get a slug of their own
+	if a, b := with.tagURL("GO"), with.tagURL("Go"); a == b || a == with.tagURL("go") {
+		t.Errorf("hidden tags share a slug: %q, %q", a, b)
+	}
+}
@@ -0,0 +1,108 @@
+package generator
+
+import (
+	"strings"
+
+	"pure/internal/fetcher"
+)
+
+// Publishing configures the publication states of discussions. A discussion
+// is a draft or unlisted when it has one of the labels below or sets draft
+// or unlisted in its front matter, and scheduled while its front matter
+// publishDate lies in the future.
+type Publishing struct {
+	// DraftLabels mark drafts, which are only built with Drafts set
+	DraftLabels []string
+	// UnlistedLabels mark unlisted posts, whose page is built but left out
+	// of the index, tag, category and archive pages, feeds, sitemap and search
+	UnlistedLabels []string
+	// Drafts builds drafts and scheduled posts too, for previewing them
+	Drafts bool
+}
+
+// hasLabel reports whether the discussion has one of the labels
+func hasLabel(discussion fetcher.Discussion, labels []string) bool {
+	for _, label := range discussion.Labels {
+		if isOneOf(label.Name, labels) {
+			return true
+		}
+	}
+	return false
+}
+
+// isOneOf reports whether the label name is one of the names, ignoring case
+// and the braces some label names are wrapped in
+func isOneOf(label string, names []string) bool {
+	for _, name := range names {
+		if strings.EqualFold(trimBraces(label), trimBraces(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDraft reports whether the discussion is a draft
+func (g *SiteGenerator) isDraft(discussion fetcher.Discussion) bool {
+	return discussion.FrontMatter.Draft || hasLabel(discussion, g.config.Build.Publishing.DraftLabels)
+}
+
+// isUnlisted reports whether the discussion is unlisted
+func (g *SiteGenerator) isUnlisted(discussion fetcher.Discussion) bool {
+	return discussion.FrontMatter.Unlisted || hasLabel(discussion, g.config.Build.Publishing.UnlistedLabels)
+}
+
+// isScheduled reports whether the discussion is to be published after the
+// current build
+func (g *SiteGenerator) isScheduled(discussion fetcher.Discussion) bool {
+	return discussion.FrontMatter.PublishDate.After(g.buildTime)
+}
+
+// publishedDiscussions drops the discussions of hidden categories, and
+// drafts and scheduled discussions unless drafts are built. The about page
+// is kept, since it is configured explicitly.
+func (g *SiteGenerator) publishedDiscussions(discussions []fetcher.Discussion) []fetcher.Discussion {
+	var published []fetcher.Discussion
+	for _, discussion := range discussions {
+		if discussion.Number != g.config.Site.AboutID {
+			if g.categoryConfig(discussion.Category.Name).Hidden {
+				continue
+			}
+			if !g.config.Build.Publishing.Drafts && (g.isDraft(discussion) || g.isScheduled(discussion)) {
+				continue
+			}
+		}
+		published = append(published, discussion)
+	}
+	return published
+}
+
+// stripStateLabels removes the labels that mark publication states, so that
+// they are not shown as tags
+func (g *SiteGenerator) stripStateLabels(post *Post) {
+	publishing := g.config.Build.Publishing
+	states := append(append([]string(nil), publishing.DraftLabels...), publishing.UnlistedLabels...)
+
+	labels := post.Labels[:0:0]
+	for _, label := range post.Labels {
+		if !isOneOf(label.Name, states) {
+			labels = append(labels, label)
+		}
+	}
+	post.Labels = labels
+}
+
+// noIndex reports whether search engines are asked not to index the post
+func (p Post) noIndex() bool {
+	return p.FrontMatter.NoIndex || p.Draft || p.Unlisted || p.Scheduled
+}
+
+// listedPosts returns the posts that appear in listings, feeds and search
+func listedPosts(posts []Post) []Post {
+	var listed []Post
+	for _, post := range posts {
+		if !post.Unlisted {
+			listed = append(listed, post)
+		}
+	}
+	return listed
+}
@@ -0,0 +1,146 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+	"unicode/utf8"
+
+	"pure/entities"
+)
+
+// questionTemplate renders discussions of Q&A categories, unless their
+// category is configured with a template of its own
+const questionTemplate = "question.html"
+
+// jsonLDAnswer is a schema.org Answer
+type jsonLDAnswer struct {
+	Type          string        `json:"@type"`
+	Text          string        `json:"text"`
+	DatePublished string        `json:"datePublished"`
+	URL           string        `json:"url,omitempty"`
+	Author        *jsonLDPerson `json:"author,omitempty"`
+}
+
+// jsonLDQuestion is a schema.org Question with its answers
+type jsonLDQuestion struct {
+	Type            string         `json:"@type"`
+	Name            string         `json:"name"`
+	Text            string         `json:"text"`
+	AnswerCount     int            `json:"answerCount"`
+	DatePublished   string         `json:"datePublished"`
+	Author          *jsonLDPerson  `json:"author,omitempty"`
+	AcceptedAnswer  *jsonLDAnswer  `json:"acceptedAnswer,omitempty"`
+	SuggestedAnswer []jsonLDAnswer `json:"suggestedAnswer,omitempty"`
+}
+
+// jsonLDQAPage is a schema.org QAPage
+type jsonLDQAPage struct {
+	Context    string         `json:"@context"`
+	Type       string         `json:"@type"`
+	URL        string         `json:"url"`
+	MainEntity jsonLDQuestion `json:"mainEntity"`
+}
+
+// qaPageJSONLD renders a Q&A discussion as a schema.org QAPage, with the
+// chosen answer as accepted answer and the other comments as suggested ones
+func qaPageJSONLD(post Post, meta Meta) template.JS {
+	question := jsonLDQuestion{
+		Type:          "Question",
+		Name:          post.Title,
+		Text:          excerpt(post.Body, utf8.RuneCountInString(post.Body)),
+		DatePublished: meta.Published.Format(time.RFC3339),
+	}
+	if meta.Author != "" {
+		question.Author = &jsonLDPerson{Type: "Person", Name: meta.Author}
+	}
+	if post.Answer != nil {
+		answer := toJSONLDAnswer(*post.Answer)
+		question.AcceptedAnswer = &answer
+		question.AnswerCount++
+	}
+	for _, comment := range post.ListedComments() {
+		question.SuggestedAnswer = append(question.SuggestedAnswer, toJSONLDAnswer(comment))
+		question.AnswerCount++
+	}
+
+	page := jsonLDQAPage{
+		Context:    "https://schema.org",
+		Type:       "QAPage",
+		URL:        meta.URL,
+		MainEntity: question,
+	}
+
+	// json.Marshal escapes <, > and &, so the output can't close the script
+	output, err := json.Marshal(page)
+	if err != nil {
+		return ""
+	}
+	return template.JS(output)
+}
+
+// ListedComments returns the comments listed below the post. The accepted
+// answer of a question is left out, as it is already shown above them.
+func (p Post) ListedComments() []entities.Comment {
+	if !p.Answerable {
+		return p.Comments
+	}
+	var comments []entities.Comment
+	for _, comment := range p.Comments {
+		if comment.IsAnswer || (p.Answer != nil && comment.ID == p.Answer.ID) {
+			continue
+		}
+		comments = append(comments, comment)
+	}
+	return comments
+}
+
+// toJSONLDAnswer converts a discussion comment into a schema.org Answer
+func toJSONLDAnswer(comment entities.Comment) jsonLDAnswer {
+	answer := jsonLDAnswer{
+		Type:          "Answer",
+		Text:          excerpt(comment.Body, utf8.RuneCountInString(comment.Body)),
+		DatePublished: comment.CreatedAt.Format(time.RFC3339),
+		URL:           comment.URL,
+	}
+	if comment.Author != "" {
+		answer.Author = &jsonLDPerson{Type: "Person", Name: comment.Author}
+	}
+	return answer
+}
+
+// generateFAQPage writes /faq/, listing the questions of all Q&A categories
+// with their accepted answers. Sites without Q&A discussions get no page.
+func (g *SiteGenerator) generateFAQPage(discussions []Post) error {
+	var questions []Post
+	for _, discussion := range discussions {
+		if discussion.Answerable && (g.config.Site.AboutID <= 0 || discussion.Number != g.config.Site.AboutID) {
+			questions = append(questions, discussion)
+		}
+	}
+	if len(questions) == 0 {
+		return nil
+	}
+
+	if err := g.renderPage("/faq/", func(w io.Writer) error {
+		data := struct {
+			Site      Config
+			Questions []Post
+		}{
+			Site:      g.config,
+			Questions: questions,
+		}
+
+		if err := g.templates.ExecuteTemplate(w, "faq.html", data); err != nil {
+			return fmt.Errorf("failed to execute faq template: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	g.addToSitemap("/faq/", feedUpdated(questions))
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+// Default reading speeds, in CJK characters and Latin words per minute
+const (
+	defaultCJKPerMinute   = 300
+	defaultWordsPerMinute = 200
+)
+
+// ReadingSpeed configures the reading speeds reading times are estimated with
+type ReadingSpeed struct {
+	// CJK is the number of Chinese, Japanese or Korean characters read per minute
+	CJK int
+	// Latin is the number of words in other scripts read per minute
+	Latin int
+}
+
+// countWords counts the CJK characters and the Latin words of the text a
+// reader sees in markdown. Code blocks and raw HTML are skipped.
+func countWords(markdown string) (cjk, latin int) {
+	parseMarkdown(markdown).Walk(func(node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+		if !entering {
+			return blackfriday.GoToNext
+		}
+		switch node.Type {
+		case blackfriday.CodeBlock, blackfriday.HTMLBlock, blackfriday.HTMLSpan:
+			return blackfriday.SkipChildren
+		case blackfriday.Text, blackfriday.Code:
+			c, l := countText(string(node.Literal))
+			cjk += c
+			latin += l
+		}
+		return blackfriday.GoToNext
+	})
+	return cjk, latin
+}
+
+// countText counts CJK characters one by one, and runs of other letters and
+// digits as words, so "Go 的并发模型" is one word and four characters
+func countText(text string) (cjk, latin int) {
+	inWord := false
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			cjk++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsNumber(r) || (inWord && (r == '\'' || r == '’')):
+			if !inWord {
+				latin++
+			}
+			inWord = true
+		default:
+			inWord = false
+		}
+	}
+	return cjk, latin
+}
+
+// readingMinutes estimates the minutes it takes to read the text, rounding
+// up to at least one minute
+func (g *SiteGenerator) readingMinutes(cjk, latin int) int {
+	speed := g.config.Build.ReadingSpeed
+	if speed.CJK <= 0 {
+		speed.CJK = defaultCJKPerMinute
+	}
+	if speed.Latin <= 0 {
+		speed.Latin = defaultWordsPerMinute
+	}
+
+	minutes := float64(cjk)/float64(speed.CJK) + float64(latin)/float64(speed.Latin)
+	return int(math.Max(1, math.Ceil(minutes)))
+}
+
+// postLanguage returns the language reading times of a post are shown in: the
+// site language, or Chinese when it is unset and most of the post is CJK text
+func (g *SiteGenerator) postLanguage(cjk, latin int) string {
+	if language := g.config.Site.Language; language != "" {
+		return language
+	}
+	if cjk > latin {
+		return "zh"
+	}
+	return ""
+}
+
+// postReadingTime formats the reading time of a post in its language
+func postReadingTime(post Post) string {
+	return formatReadingTime(post.Language, post.ReadingTime)
+}
+
+// postWordCount formats the word count of a post in its language
+func postWordCount(post Post) string {
+	return formatWordCount(post.Language, post.WordCount)
+}
+
+// isChinese reports whether the site language is a Chinese variant
+func isChinese(language string) bool {
+	language = strings.ToLower(language)
+	return language == "zh" || strings.HasPrefix(language, "zh-") || strings.HasPrefix(language, "zh_")
+}
+
+// formatReadingTime formats an estimated reading time in the site language
+func formatReadingTime(language string, minutes int) string {
+	if isChinese(language) {
+		return fmt.Sprintf("约 %d 分钟", minutes)
+	}
+	return fmt.Sprintf("%d min read", minutes)
+}
+
+// formatWordCount formats a word count in the site language
+func formatWordCount(language string, words int) string {
+	if isChinese(language) {
+		return fmt.Sprintf("%d 字", words)
+	}
+	if words == 1 {
+		return "1 word"
+	}
+	return fmt.Sprintf("%d words", words)
+}
@@ -0,0 +1,62 @@
+package generator
+
+import "testing"
+
+func TestCountWords(t *testing.T) {
+	tests := []struct {
+		name      string
+		markdown  string
+		wantCJK   int
+		wantLatin int
+	}{
+		{"chinese", "今天学习并发", 6, 0},
+		{"english", "Goroutines are cheap, aren't they?", 0, 5},
+		{"mixed", "用 Go 写一个 HTTP server", 4, 3},
+		{"no space between scripts", "Go的并发模型", 5, 1},
+		{"japanese and korean", "ひらがな カタカナ 한국어", 11, 0},
+		{"numbers", "Go 1.22 发布了", 3, 3},
+		{"inline code", "运行 `go test` 即可", 4, 2},
+		{"code block", "示例：\n\n```go\nfmt.Println(\"你好\")\n```\n\nDone", 2, 1},
+		{"html", "段落 <span>跳过</span>\n\n<div>\n忽略 ignored\n</div>\n", 4, 0},
+		{"markup", "## 标题 Title\n\n- **加粗** and [链接](https://example.com)", 6, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cjk, latin := countWords(tt.markdown)
+			if cjk != tt.wantCJK || latin != tt.wantLatin {
+				t.Errorf("countWords(%q) = %d, %d, want %d, %d", tt.markdown, cjk, latin, tt.wantCJK, tt.wantLatin)
+			}
+		})
+	}
+}
+
+func TestPostReadingTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		cjk      int
+		latin    int
+		want     string
+	}{
+		{"site language", "zh-CN", 2400, 0, "约 8 分钟"},
+		{"site language over content", "en", 2400, 0, "8 min read"},
+		{"chinese site with english post", "zh", 0, 400, "约 2 分钟"},
+		{"unset with mostly cjk", "", 900, 200, "约 4 分钟"},
+		{"unset with mostly latin", "", 100, 500, "3 min read"},
+		{"short post", "", 10, 0, "约 1 分钟"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &SiteGenerator{config: Config{Site: Site{Language: tt.language}}}
+			post := Post{
+				Language:    g.postLanguage(tt.cjk, tt.latin),
+				ReadingTime: g.readingMinutes(tt.cjk, tt.latin),
+			}
+			if got := postReadingTime(post); got != tt.want {
+				t.Errorf("reading time = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+)
+
+// redirectTemplate is the page written at former post URLs. Static hosting
+// can't send HTTP redirects, so it redirects with a meta refresh and tells
+// search engines where the content moved to.
+var redirectTemplate = template.Must(template.New("redirect").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Redirecting…</title>
+    <link rel="canonical" href="{{.}}">
+    <meta name="robots" content="noindex">
+    <meta http-equiv="refresh" content="0; url={{.}}">
+</head>
+<body>
+    <p>This page has moved to <a href="{{.}}">{{.}}</a>.</p>
+</body>
+</html>
+`))
+
+// redirectMarker identifies the redirect stubs of earlier builds, which may
+// be overwritten
+var redirectMarker = []byte(`<meta http-equiv="refresh"`)
+
+// generateRedirects writes redirect stubs at the former URLs of every post.
+// It runs after all other pages are written, so that a stub never replaces
+// one of them.
+func (g *SiteGenerator) generateRedirects(discussions []Post) error {
+	patterns := g.config.Build.Redirects
+	if len(patterns) == 0 && g.permalinkPattern() != defaultPermalink {
+		patterns = []string{defaultPermalink}
+	}
+
+	for _, discussion := range discussions {
+		for _, pattern := range patterns {
+			from := g.expandPermalink(pattern, discussion)
+			if from == discussion.Path {
+				continue
+			}
+			if err := g.writeRedirect(from, discussion.Path); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeRedirect writes a redirect stub at the site-relative path from. Paths
+// taken by a page of this build, or by a file other than a stub of an earlier
+// build, are skipped with a warning.
+func (g *SiteGenerator) writeRedirect(from, to string) error {
+	path, err := g.checkPath(from)
+	if err != nil {
+		return fmt.Errorf("invalid redirect: %w", err)
+	}
+	existing, err := os.ReadFile(filepath.Join(path, "index.html"))
+	if g.written(from) || (err == nil && !bytes.Contains(existing, redirectMarker)) {
+		fmt.Printf("Warning: not redirecting %s to %s, a page already exists there\n", from, to)
+		return nil
+	}
+
+	dir, err := g.outputPath(from)
+	if err != nil {
+		return fmt.Errorf("invalid redirect: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create redirect directory: %w", err)
+	}
+
+	file, err := os.Create(filepath.Join(dir, "index.html"))
+	if err != nil {
+		return fmt.Errorf("failed to create redirect index.html: %w", err)
+	}
+	defer file.Close()
+
+	if err := redirectTemplate.Execute(file, g.absURL(to)); err != nil {
+		return fmt.Errorf("failed to execute redirect template: %w", err)
+	}
+
+	return nil
+}
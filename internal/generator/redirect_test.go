@@ -0,0 +1,67 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteRedirectKeepsPages(t *testing.T) {
+	dir := t.TempDir()
+	g := &SiteGenerator{outputDir: dir, config: Config{Site: Site{URL: "https://example.com"}}}
+
+	write := func(urlPath, content string) {
+		t.Helper()
+		path := filepath.Join(dir, filepath.FromSlash(urlPath), "index.html")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(urlPath string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(urlPath), "index.html"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	// A page of this build
+	if _, err := g.outputPath("/posts/1/"); err != nil {
+		t.Fatal(err)
+	}
+	write("/posts/1/", "post one")
+	// A file this build didn't write, such as a memo page
+	write("/posts/2/", "memo")
+	// A stub of an earlier build
+	write("/posts/3/", `<meta http-equiv="refresh" content="0; url=https://example.com/old/">`)
+
+	for _, from := range []string{"/posts/1/", "/posts/2/", "/posts/3/", "/posts/4/"} {
+		if err := g.writeRedirect(from, "/hello/"); err != nil {
+			t.Fatalf("writeRedirect(%q) error = %v", from, err)
+		}
+	}
+
+	if got := read("/posts/1/"); got != "post one" {
+		t.Errorf("page of this build was replaced by %q", got)
+	}
+	if got := read("/posts/2/"); got != "memo" {
+		t.Errorf("existing page was replaced by %q", got)
+	}
+	for _, from := range []string{"/posts/3/", "/posts/4/"} {
+		if got := read(from); !strings.Contains(got, "https://example.com/hello/") {
+			t.Errorf("%s = %q, want a redirect to /hello/", from, got)
+		}
+	}
+	// Two posts with the same former URL
+	if err := g.writeRedirect("/posts/4/", "/other/"); err != nil {
+		t.Fatal(err)
+	}
+	if got := read("/posts/4/"); !strings.Contains(got, "https://example.com/hello/") {
+		t.Errorf("second redirect replaced the first: %q", got)
+	}
+}
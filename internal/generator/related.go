@@ -0,0 +1,182 @@
+package generator
+
+import (
+	"math"
+	"sort"
+	"unicode/utf8"
+
+	"pure/internal/search"
+)
+
+// defaultRelatedPosts is the number of related posts shown when
+// Build.RelatedPosts is unset
+const defaultRelatedPosts = 5
+
+// Weights of the signals posts are related by. Text similarity is a cosine
+// between 0 and 1, the others count matches.
+const (
+	relatedLabelWeight    = 1.0
+	relatedCategoryWeight = 0.5
+	relatedTextWeight     = 3.0
+	// minRelatedScore keeps posts that only share a few common words out
+	minRelatedScore = 0.3
+)
+
+// termWeight is an entry of a sparse TF-IDF vector
+type termWeight struct {
+	term   int
+	weight float64
+}
+
+// relatedPosts picks the related posts of every post, keyed by discussion
+// number. Posts are scored by shared labels, a shared category and the TF-IDF
+// similarity of their text; ties go to the newer post, then the lower number,
+// so that the result only changes when the posts do. The about page is left
+// out on both sides, unlisted posts get related posts but are never suggested.
+func (g *SiteGenerator) relatedPosts(posts []Post) map[int][]Post {
+	count := g.config.Build.RelatedPosts
+	if count == 0 {
+		count = defaultRelatedPosts
+	}
+	if count < 0 {
+		return nil
+	}
+
+	var candidates []Post
+	for _, post := range posts {
+		if g.config.Site.AboutID <= 0 || post.Number != g.config.Site.AboutID {
+			candidates = append(candidates, post)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Number < candidates[j].Number
+	})
+
+	vectors := tfidfVectors(candidates)
+	related := make(map[int][]Post, len(candidates))
+
+	type scored struct {
+		post  Post
+		score float64
+	}
+	for i, post := range candidates {
+		var matches []scored
+		for j, other := range candidates {
+			if i == j || other.Unlisted {
+				continue
+			}
+
+			score := relatedLabelWeight * float64(sharedLabels(post, other))
+			if post.Category.Name != "" && post.Category.Name == other.Category.Name {
+				score += relatedCategoryWeight
+			}
+			score += relatedTextWeight * cosine(vectors[i], vectors[j])
+
+			if score >= minRelatedScore {
+				matches = append(matches, scored{post: other, score: score})
+			}
+		}
+
+		sort.SliceStable(matches, func(a, b int) bool {
+			if matches[a].score != matches[b].score {
+				return matches[a].score > matches[b].score
+			}
+			if !matches[a].post.PublishedAt().Equal(matches[b].post.PublishedAt()) {
+				return matches[a].post.PublishedAt().After(matches[b].post.PublishedAt())
+			}
+			return matches[a].post.Number < matches[b].post.Number
+		})
+
+		for k := 0; k < len(matches) && k < count; k++ {
+			related[post.Number] = append(related[post.Number], matches[k].post)
+		}
+	}
+
+	return related
+}
+
+// sharedLabels counts the labels two posts have in common
+func sharedLabels(a, b Post) int {
+	labels := make(map[string]bool, len(a.Labels))
+	for _, label := range a.Labels {
+		labels[label.Name] = true
+	}
+
+	shared := 0
+	for _, label := range b.Labels {
+		if labels[label.Name] {
+			shared++
+			labels[label.Name] = false
+		}
+	}
+	return shared
+}
+
+// tfidfVectors returns the normalized TF-IDF vector of the title and text of
+// every post, tokenized like the search index. Vectors are sorted by term so
+// that similarities are summed in a fixed order.
+func tfidfVectors(posts []Post) [][]termWeight {
+	termIDs := make(map[string]int)
+	frequencies := make([]map[int]int, len(posts))
+	documentFrequency := make(map[int]int)
+
+	for i, post := range posts {
+		text := post.Title + " " + excerpt(post.Body, utf8.RuneCountInString(post.Body))
+		frequencies[i] = make(map[int]int)
+		for _, term := range search.Tokenize(text) {
+			id, ok := termIDs[term]
+			if !ok {
+				id = len(termIDs)
+				termIDs[term] = id
+			}
+			if frequencies[i][id] == 0 {
+				documentFrequency[id]++
+			}
+			frequencies[i][id]++
+		}
+	}
+
+	vectors := make([][]termWeight, len(posts))
+	for i, terms := range frequencies {
+		vector := make([]termWeight, 0, len(terms))
+		for term, frequency := range terms {
+			idf := math.Log(float64(len(posts)) / float64(documentFrequency[term]))
+			if idf > 0 {
+				vector = append(vector, termWeight{term: term, weight: (1 + math.Log(float64(frequency))) * idf})
+			}
+		}
+		sort.Slice(vector, func(a, b int) bool {
+			return vector[a].term < vector[b].term
+		})
+
+		var norm float64
+		for _, entry := range vector {
+			norm += entry.weight * entry.weight
+		}
+		norm = math.Sqrt(norm)
+		for k := range vector {
+			vector[k].weight /= norm
+		}
+		vectors[i] = vector
+	}
+
+	return vectors
+}
+
+// cosine returns the cosine similarity of two normalized sparse vectors
+func cosine(a, b []termWeight) float64 {
+	var dot float64
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i].term < b[j].term:
+			i++
+		case a[i].term > b[j].term:
+			j++
+		default:
+			dot += a[i].weight * b[j].weight
+			i++
+			j++
+		}
+	}
+	return dot
+}
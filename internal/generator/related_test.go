@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// numbers returns the discussion numbers of posts
+func numbers(posts []Post) []int {
+	var numbers []int
+	for _, post := range posts {
+		numbers = append(numbers, post.Number)
+	}
+	return numbers
+}
+
+func TestRelatedPosts(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 10, 0, 0, 0, time.UTC) }
+	post := func(number, created int, labels ...string) Post {
+		p := labeledPost(number, labels...)
+		p.CreatedAt = day(created)
+		return p
+	}
+
+	posts := []Post{
+		post(1, 1, "go", "web"),
+		// Equally related to 1 by a single label: the newer ones come first,
+		// and 3 before 4 as they were published at the same time
+		post(2, 2, "go"),
+		post(3, 5, "go"),
+		post(4, 5, "go"),
+		// More related to 1 than the others
+		post(5, 3, "go", "web"),
+		post(6, 4, "go", "web"),
+		// Unrelated
+		post(7, 6, "rust"),
+	}
+	unlisted := post(8, 7, "go", "web")
+	unlisted.Unlisted = true
+	about := post(9, 8, "go", "web")
+	posts = append(posts, unlisted, about)
+
+	g := &SiteGenerator{config: Config{Site: Site{AboutID: 9}, Build: Build{RelatedPosts: 4}}}
+	want := map[int][]int{
+		1: {6, 5, 3, 4},
+		2: {3, 4, 6, 5},
+		5: {6, 1, 3, 4},
+		8: {6, 5, 1, 3},
+	}
+
+	related := g.relatedPosts(posts)
+	for number, wantNumbers := range want {
+		if got := numbers(related[number]); !reflect.DeepEqual(got, wantNumbers) {
+			t.Errorf("related posts of %d = %v, want %v", number, got, wantNumbers)
+		}
+	}
+	for number, others := range related {
+		for _, other := range others {
+			if other.Number == number || other.Number == 8 || other.Number == 9 {
+				t.Errorf("related posts of %d include %d", number, other.Number)
+			}
+		}
+	}
+	if got := related[7]; len(got) != 0 {
+		t.Errorf("related posts of 7 = %v, want none", numbers(got))
+	}
+	if _, ok := related[9]; ok {
+		t.Error("the about page has related posts")
+	}
+
+	// The order the posts come in does not matter
+	reversed := make([]Post, len(posts))
+	for i, post := range posts {
+		reversed[len(posts)-1-i] = post
+	}
+	if got := g.relatedPosts(reversed); !reflect.DeepEqual(got, related) {
+		t.Error("related posts depend on the order of the posts")
+	}
+}
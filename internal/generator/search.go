@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"pure/entities"
+	"pure/internal/search"
+)
+
+// summaryLength is the length of search result summaries in characters
+const summaryLength = 200
+
+// generateSearchIndex adds the posts to the search index
+func (g *SiteGenerator) generateSearchIndex(discussions []Post) error {
+	documents := make([]search.Document, 0, len(discussions))
+	for _, discussion := range discussions {
+		// Q&A discussions are found by their accepted answer as well
+		body := discussion.Body
+		if discussion.Answer != nil {
+			body += "\n\n" + discussion.Answer.Body
+		}
+		documents = append(documents, search.Document{
+			Type:    "post",
+			Title:   discussion.Title,
+			URL:     discussion.Permalink,
+			Date:    discussion.PublishedAt().Format("2006-01-02"),
+			Tags:    postCategories(discussion),
+			Summary: postSummary(discussion),
+			Text:    excerpt(body, utf8.RuneCountInString(body)),
+
+			WordCount:   discussion.WordCount,
+			ReadingTime: discussion.ReadingTime,
+		})
+	}
+
+	return updateSearchIndex(g.outputDir, "posts", documents)
+}
+
+// generateSearchPage writes the search page at /search/
+func (g *SiteGenerator) generateSearchPage() error {
+	return g.renderPage("/search/", func(w io.Writer) error {
+		data := struct {
+			Site Config
+		}{
+			Site: g.config,
+		}
+
+		if err := g.templates.ExecuteTemplate(w, "search.html", data); err != nil {
+			return fmt.Errorf("failed to execute search template: %w", err)
+		}
+		return nil
+	})
+}
+
+// generateSearchIndex adds the memos to the search index
+func (g *NotesGenerator) generateSearchIndex(notes []entities.Note) error {
+	documents := make([]search.Document, 0, len(notes))
+	for _, note := range notes {
+		text := strings.Join(strings.Fields(note.Content), " ")
+		summary := text
+		if utf8.RuneCountInString(summary) > summaryLength {
+			summary = string([]rune(summary)[:summaryLength]) + "…"
+		}
+
+		documents = append(documents, search.Document{
+			Type:    "memo",
+			Title:   g.noteTitle(note),
+			URL:     g.notePermalink(note),
+			Date:    note.CreatedAt.Format("2006-01-02"),
+			Tags:    note.Tags,
+			Summary: summary,
+			Text:    text,
+		})
+	}
+
+	return updateSearchIndex(g.outputDir, "memos", documents)
+}
+
+// updateSearchIndex replaces the documents of one source in the index and
+// keeps the others, so the blog and memos can be generated separately
+func updateSearchIndex(outputDir, source string, documents []search.Document) error {
+	return search.Update(filepath.Join(outputDir, "search"), source, documents)
+}
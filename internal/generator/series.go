@@ -0,0 +1,168 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"pure/internal/utils"
+)
+
+// seriesLabelPrefix marks labels that put a discussion into a series, as in
+// series:go-concurrency
+const seriesLabelPrefix = "series:"
+
+// Series is a group of posts read in order, such as a multi-part tutorial
+type Series struct {
+	Name string
+	URL  string
+	// Posts are the parts of the series in reading order
+	Posts []Post
+}
+
+// SeriesNav places a post within its series
+type SeriesNav struct {
+	*Series
+	// Part is the 1-based position of the post in the series
+	Part int
+	Prev *Post
+	Next *Post
+}
+
+// Total returns the number of parts of the series
+func (s *Series) Total() int {
+	return len(s.Posts)
+}
+
+// seriesLabel returns the series named by a series: label
+func seriesLabel(label string) (name string, ok bool) {
+	label = trimBraces(label)
+	if len(label) < len(seriesLabelPrefix) || !strings.EqualFold(label[:len(seriesLabelPrefix)], seriesLabelPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(label[len(seriesLabelPrefix):]), true
+}
+
+// seriesName returns the series a discussion belongs to, set by the series
+// front matter key or a series: label
+func seriesName(post Post) string {
+	if name := strings.TrimSpace(post.FrontMatter.Series); name != "" {
+		return name
+	}
+	for _, label := range post.Labels {
+		if name, ok := seriesLabel(label.Name); ok && name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// stripSeriesLabels removes series: labels, so that they are not shown as tags
+func stripSeriesLabels(post *Post) {
+	labels := post.Labels[:0:0]
+	for _, label := range post.Labels {
+		if _, ok := seriesLabel(label.Name); !ok {
+			labels = append(labels, label)
+		}
+	}
+	post.Labels = labels
+}
+
+// prepareSeries groups the listed posts into series and assigns every series
+// a unique slug used for its page below /series/. Parts with a seriesOrder
+// come first in that order, the rest follow by publish date.
+func (g *SiteGenerator) prepareSeries(posts []Post) error {
+	g.series = make(map[string]*Series)
+	g.seriesNames = nil
+	for _, post := range posts {
+		if post.SeriesName == "" || (g.config.Site.AboutID > 0 && post.Number == g.config.Site.AboutID) {
+			continue
+		}
+		series, ok := g.series[post.SeriesName]
+		if !ok {
+			series = &Series{Name: post.SeriesName}
+			g.series[post.SeriesName] = series
+			g.seriesNames = append(g.seriesNames, post.SeriesName)
+		}
+		series.Posts = append(series.Posts, post)
+	}
+	sort.Strings(g.seriesNames)
+
+	slugger := utils.NewSlugger(g.config.Build.Pinyin)
+	for _, name := range g.seriesNames {
+		series := g.series[name]
+		series.URL = "/series/" + slugger.Slug(name, "series") + "/"
+		if _, err := g.checkPath(series.URL); err != nil {
+			return fmt.Errorf("invalid series %q: %w", name, err)
+		}
+
+		sort.SliceStable(series.Posts, func(i, j int) bool {
+			a, b := series.Posts[i], series.Posts[j]
+			if a.FrontMatter.SeriesOrder != b.FrontMatter.SeriesOrder {
+				// Parts without an order go last
+				if a.FrontMatter.SeriesOrder == 0 || b.FrontMatter.SeriesOrder == 0 {
+					return b.FrontMatter.SeriesOrder == 0
+				}
+				return a.FrontMatter.SeriesOrder < b.FrontMatter.SeriesOrder
+			}
+			if !a.PublishedAt().Equal(b.PublishedAt()) {
+				return a.PublishedAt().Before(b.PublishedAt())
+			}
+			return a.Number < b.Number
+		})
+	}
+
+	return nil
+}
+
+// seriesNav returns the position of the post in its series, or nil when the
+// post is not part of one
+func (g *SiteGenerator) seriesNav(post Post) *SeriesNav {
+	series, ok := g.series[post.SeriesName]
+	if !ok {
+		return nil
+	}
+	for i, part := range series.Posts {
+		if part.Number != post.Number {
+			continue
+		}
+		nav := &SeriesNav{Series: series, Part: i + 1}
+		if i > 0 {
+			nav.Prev = &series.Posts[i-1]
+		}
+		if i < len(series.Posts)-1 {
+			nav.Next = &series.Posts[i+1]
+		}
+		return nav
+	}
+	return nil
+}
+
+// generateSeriesPages writes the landing page of every series, listing its
+// parts in reading order
+func (g *SiteGenerator) generateSeriesPages() error {
+	for _, name := range g.seriesNames {
+		series := g.series[name]
+		err := g.renderPage(series.URL, func(w io.Writer) error {
+			data := struct {
+				Site   Config
+				Series *Series
+			}{
+				Site:   g.config,
+				Series: series,
+			}
+
+			if err := g.templates.ExecuteTemplate(w, "series.html", data); err != nil {
+				return fmt.Errorf("failed to execute series template: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to generate series page for %s: %w", series.Name, err)
+		}
+		g.addToSitemap(series.URL, feedUpdated(series.Posts))
+	}
+
+	return nil
+}
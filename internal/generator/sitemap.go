@@ -0,0 +1,145 @@
+package generator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxSitemapURLs is the maximum number of URLs a single sitemap may list
+// according to the sitemap protocol
+const maxSitemapURLs = 50000
+
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// sitemapURL is a page listed in a sitemap
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+// newSitemapURL creates a sitemap entry for loc, last modified at lastmod
+func newSitemapURL(loc string, lastmod time.Time) sitemapURL {
+	entry := sitemapURL{Loc: loc}
+	if !lastmod.IsZero() {
+		entry.LastMod = lastmod.UTC().Format(time.RFC3339)
+	}
+	return entry
+}
+
+// writeSitemap writes sitemap.xml listing urls to the site-relative directory
+// dir below outputDir. With more than maxSitemapURLs URLs it is written as a
+// sitemap index referring to sitemap-1.xml, sitemap-2.xml, ... instead.
+func writeSitemap(outputDir, baseURL, dir string, urls []sitemapURL) error {
+	dirPath := filepath.Join(outputDir, filepath.FromSlash(dir))
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return fmt.Errorf("failed to create sitemap directory: %w", err)
+	}
+
+	if len(urls) <= maxSitemapURLs {
+		return writeXML(filepath.Join(dirPath, "sitemap.xml"), sitemapURLSet{Xmlns: sitemapNamespace, URLs: urls})
+	}
+
+	index := sitemapIndex{Xmlns: sitemapNamespace}
+	for part := 1; len(urls) > 0; part++ {
+		n := len(urls)
+		if n > maxSitemapURLs {
+			n = maxSitemapURLs
+		}
+
+		name := fmt.Sprintf("sitemap-%d.xml", part)
+		if err := writeXML(filepath.Join(dirPath, name), sitemapURLSet{Xmlns: sitemapNamespace, URLs: urls[:n]}); err != nil {
+			return err
+		}
+
+		var lastmod string
+		for _, u := range urls[:n] {
+			if u.LastMod > lastmod {
+				lastmod = u.LastMod
+			}
+		}
+		loc := strings.TrimSuffix(baseURL, "/") + path.Join("/", dir, name)
+		index.Sitemaps = append(index.Sitemaps, sitemapURL{Loc: loc, LastMod: lastmod})
+
+		urls = urls[n:]
+	}
+
+	return writeXML(filepath.Join(dirPath, "sitemap.xml"), index)
+}
+
+// writeXML marshals v into an XML file at path
+func writeXML(filePath string, v interface{}) error {
+	output, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", filepath.Base(filePath), err)
+	}
+
+	if err := os.WriteFile(filePath, append([]byte(xml.Header), output...), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(filePath), err)
+	}
+
+	return nil
+}
+
+// addToSitemap lists the page at the site-relative path in the sitemap
+func (g *SiteGenerator) addToSitemap(path string, lastmod time.Time) {
+	g.sitemap = append(g.sitemap, newSitemapURL(g.absURL(path), lastmod))
+}
+
+// generateSitemap writes the sitemap of all pages added to it
+func (g *SiteGenerator) generateSitemap() error {
+	if err := writeSitemap(g.outputDir, g.config.Site.URL, "/", g.sitemap); err != nil {
+		return err
+	}
+	g.sitemapPath = "/sitemap.xml"
+	return nil
+}
+
+// Sitemap returns the site-relative path of the sitemap written by Generate
+func (g *SiteGenerator) Sitemap() string {
+	return g.sitemapPath
+}
+
+// Sitemap returns the site-relative path of the memos sitemap written by
+// Generate, or "" if there were no memos to list
+func (g *NotesGenerator) Sitemap() string {
+	return g.sitemapPath
+}
+
+// WriteRobots writes robots.txt, pointing crawlers to the given site-relative
+// sitemaps. Only the sitemaps written by this run should be passed, so that a
+// stale one left in outputDir by an earlier build is not advertised. Pages
+// marked noindex are not disallowed: crawlers have to fetch them to see the
+// noindex meta tag, and they are left out of the sitemaps instead.
+func WriteRobots(outputDir, baseURL string, sitemaps []string) error {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	b.WriteString("Allow: /\n\n")
+
+	for _, sitemap := range sitemaps {
+		fmt.Fprintf(&b, "Sitemap: %s\n", strings.TrimSuffix(baseURL, "/")+escapePath(sitemap))
+	}
+
+	robotsPath := filepath.Join(outputDir, "robots.txt")
+	if err := os.WriteFile(robotsPath, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write robots.txt: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	gohtml "html"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+// defaultTOCMinHeadings is the number of headings a post needs for a table of
+// contents when TOC.MinHeadings is unset
+const defaultTOCMinHeadings = 3
+
+// TOC configures the table of contents of posts
+type TOC struct {
+	// Enabled shows a table of contents on posts, unless their front matter
+	// sets toc: false. Posts can opt in with toc: true when it is disabled.
+	Enabled bool
+	// MinHeadings is the number of headings a post needs for a table of
+	// contents, so that short posts go without one
+	MinHeadings int
+}
+
+// Heading is an entry of a table of contents
+type Heading struct {
+	Level    int
+	ID       string
+	Title    string
+	Children []*Heading
+}
+
+// markdownExtensions are the Blackfriday extensions posts are rendered with
+const markdownExtensions = blackfriday.CommonExtensions | blackfriday.AutoHeadingIDs | blackfriday.NoEmptyLineBeforeBlock
+
+// parseMarkdown parses markdown into a Blackfriday AST
+func parseMarkdown(s string) *blackfriday.Node {
+	// Clean up line endings
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+
+	parser := blackfriday.New(blackfriday.WithExtensions(markdownExtensions))
+	return parser.Parse([]byte(s))
+}
+
+// renderAST renders a Blackfriday AST to HTML
+func renderAST(w io.Writer, renderer blackfriday.Renderer, ast *blackfriday.Node) {
+	renderer.RenderHeader(w, ast)
+	ast.Walk(func(node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+		return renderer.RenderNode(w, node, entering)
+	})
+	renderer.RenderFooter(w, ast)
+}
+
+// postContent renders the body of a post together with its table of
+// contents, which is nil when the post should not have one
+func (g *SiteGenerator) postContent(post Post) (template.HTML, []*Heading) {
+	ast := parseMarkdown(post.Body)
+	headings := assignHeadingIDs(ast)
+
+	renderer := &ChromaRenderer{
+		HTML: blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{
+			Flags: blackfriday.UseXHTML,
+		}),
+		HeadingAnchors: true,
+	}
+	var buf bytes.Buffer
+	renderAST(&buf, renderer, ast)
+	content := template.HTML(buf.String())
+
+	enabled := g.config.Build.TOC.Enabled
+	if post.FrontMatter.TOC != nil {
+		enabled = *post.FrontMatter.TOC
+	}
+	minHeadings := g.config.Build.TOC.MinHeadings
+	if minHeadings <= 0 {
+		minHeadings = defaultTOCMinHeadings
+	}
+	if !enabled || len(headings) < minHeadings {
+		return content, nil
+	}
+
+	return content, nestHeadings(headings)
+}
+
+// assignHeadingIDs gives every heading in the AST an anchor ID that is unique
+// within the document and returns the headings in document order. IDs come
+// from the heading text as with AutoHeadingIDs, or from an explicit {#id};
+// repeated ones get a -2, -3, ... suffix.
+func assignHeadingIDs(ast *blackfriday.Node) []*Heading {
+	var headings []*Heading
+	used := make(map[string]bool)
+
+	ast.Walk(func(node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+		if !entering || node.Type != blackfriday.Heading || node.IsTitleblock {
+			return blackfriday.GoToNext
+		}
+
+		title := strings.Join(strings.Fields(nodeText(node)), " ")
+		id := node.HeadingID
+		if id == "" {
+			id = blackfriday.SanitizedAnchorName(title)
+		}
+		if id == "" {
+			id = "section"
+		}
+		unique := id
+		for n := 2; used[unique]; n++ {
+			unique = fmt.Sprintf("%s-%d", id, n)
+		}
+		used[unique] = true
+		node.HeadingID = unique
+
+		headings = append(headings, &Heading{
+			Level: node.Level,
+			ID:    unique,
+			Title: title,
+		})
+		return blackfriday.SkipChildren
+	})
+
+	return headings
+}
+
+// nodeText returns the plain text inside node
+func nodeText(node *blackfriday.Node) string {
+	var text strings.Builder
+	node.Walk(func(n *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+		if entering && (n.Type == blackfriday.Text || n.Type == blackfriday.Code) {
+			text.Write(n.Literal)
+		}
+		return blackfriday.GoToNext
+	})
+	return text.String()
+}
+
+// nestHeadings arranges headings into a tree by their level, so that an h3
+// following an h2 becomes its child. Headings that skip a level are nested
+// under the closest preceding heading of a higher level.
+func nestHeadings(headings []*Heading) []*Heading {
+	var roots []*Heading
+	var stack []*Heading
+
+	for _, heading := range headings {
+		for len(stack) > 0 && stack[len(stack)-1].Level >= heading.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, heading)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, heading)
+		}
+		stack = append(stack, heading)
+	}
+
+	return roots
+}
+
+// writeHeadingAnchor writes the self-link readers use to share a section
+func writeHeadingAnchor(w io.Writer, id string) {
+	fmt.Fprintf(w, `<a class="heading-anchor" href="#%s" aria-label="Link to this section">#</a>`, gohtml.EscapeString(id))
+}
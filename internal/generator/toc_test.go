@@ -0,0 +1,58 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+// outline renders a heading tree as "title(children) title(children)"
+func outline(headings []*Heading) string {
+	var parts []string
+	for _, heading := range headings {
+		part := heading.Title
+		if len(heading.Children) > 0 {
+			part += "(" + outline(heading.Children) + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestNestHeadings(t *testing.T) {
+	tests := []struct {
+		name   string
+		levels []int
+		want   string
+	}{
+		{"flat", []int{2, 2, 2}, "a b c"},
+		{"nested", []int{2, 3, 3, 2, 3}, "a(b c) d(e)"},
+		{"skipped level", []int{2, 4, 3, 2}, "a(b c) d"},
+		{"skipped level back up", []int{2, 4, 4, 2}, "a(b c) d"},
+		{"starts with h3", []int{3, 3, 2, 3}, "a b c(d)"},
+		{"deeper first heading", []int{4, 2, 3}, "a b(c)"},
+		{"h1 above h2", []int{1, 2, 3, 2}, "a(b(c) d)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headings := make([]*Heading, len(tt.levels))
+			for i, level := range tt.levels {
+				headings[i] = &Heading{Level: level, Title: string(rune('a' + i))}
+			}
+			if got := outline(nestHeadings(headings)); got != tt.want {
+				t.Errorf("nestHeadings(%v) = %s, want %s", tt.levels, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssignHeadingIDs(t *testing.T) {
+	ast := parseMarkdown("## Intro\n\n#### Deep\n\n## Intro\n\n### Custom {#setup}\n")
+	var ids []string
+	for _, heading := range assignHeadingIDs(ast) {
+		ids = append(ids, heading.ID)
+	}
+	if got, want := strings.Join(ids, " "), "intro deep intro-2 setup"; got != want {
+		t.Errorf("ids = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,221 @@
+// Package search builds the static full-text search index of the site.
+//
+// Generators add the documents they produce to the index with Update, and
+// the browser queries it without a server:
+//
+//	search/index.json         document list and shard names
+//	search/shards/<key>.json  postings of every term in the shard
+//
+// The blog and the memos are generated separately, so Update replaces the
+// documents of one source and keeps the postings of the others from the
+// existing index. The text of the documents is only tokenized, never written.
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// indexVersion is bumped whenever the index layout changes
+const indexVersion = 2
+
+// titleWeight is how many body occurrences a term in the title counts as
+const titleWeight = 5
+
+// Document is a searchable page
+type Document struct {
+	// Type is the kind of page, such as post or memo
+	Type    string   `json:"type"`
+	Title   string   `json:"title"`
+	URL     string   `json:"url"`
+	Date    string   `json:"date"`
+	Tags    []string `json:"tags,omitempty"`
+	Summary string   `json:"summary"`
+	// WordCount and ReadingTime, in minutes, are shown with results
+	WordCount   int `json:"wordCount,omitempty"`
+	ReadingTime int `json:"readingTime,omitempty"`
+	// Text is the plain text body that gets indexed
+	Text string `json:"-"`
+}
+
+// indexDocument is a document as listed in index.json
+type indexDocument struct {
+	Document
+	// Source is the generator the document came from
+	Source string `json:"source"`
+	// Length is the number of terms in the document, used for ranking
+	Length int `json:"length"`
+}
+
+type index struct {
+	Version   int             `json:"version"`
+	Documents []indexDocument `json:"documents"`
+	// AverageLength is the mean document length, used for ranking
+	AverageLength float64  `json:"averageLength"`
+	Shards        []string `json:"shards"`
+}
+
+// posting lists a document containing a term: its index in
+// index.json's documents and the term's weighted frequency
+type posting [2]int
+
+// Update replaces the documents of one source and rebuilds the index in dir,
+// keeping the documents of all other sources
+func Update(dir, source string, documents []Document) error {
+	docs, terms, err := readIndex(dir, source)
+	if err != nil {
+		return err
+	}
+
+	for _, doc := range documents {
+		frequencies, length := termFrequencies(doc)
+		doc.Text = ""
+		docs = append(docs, indexDocument{Document: doc, Source: source, Length: length})
+		terms = append(terms, frequencies)
+	}
+	// Sort by source so that the index doesn't depend on the order the
+	// sources were updated in
+	order := make([]int, len(docs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return docs[order[i]].Source < docs[order[j]].Source
+	})
+
+	idx := index{Version: indexVersion}
+	shards := make(map[string]map[string][]posting)
+	totalLength := 0
+	for _, i := range order {
+		n := len(idx.Documents)
+		idx.Documents = append(idx.Documents, docs[i])
+		totalLength += docs[i].Length
+
+		for term, frequency := range terms[i] {
+			key := shardKey(term)
+			if shards[key] == nil {
+				shards[key] = make(map[string][]posting)
+			}
+			shards[key][term] = append(shards[key][term], posting{n, frequency})
+		}
+	}
+	if len(idx.Documents) > 0 {
+		idx.AverageLength = float64(totalLength) / float64(len(idx.Documents))
+	}
+
+	// Earlier versions kept the full text of every document in sources/
+	if err := os.RemoveAll(filepath.Join(dir, "sources")); err != nil {
+		return fmt.Errorf("failed to remove old search sources: %w", err)
+	}
+
+	// Replace the shards of the previous build
+	shardsDir := filepath.Join(dir, "shards")
+	if err := os.RemoveAll(shardsDir); err != nil {
+		return fmt.Errorf("failed to remove old search shards: %w", err)
+	}
+	if err := os.MkdirAll(shardsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create search shards directory: %w", err)
+	}
+
+	for key, terms := range shards {
+		data, err := json.Marshal(terms)
+		if err != nil {
+			return fmt.Errorf("failed to marshal search shard: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(shardsDir, key+".json"), data, 0644); err != nil {
+			return fmt.Errorf("failed to write search shard: %w", err)
+		}
+		idx.Shards = append(idx.Shards, key)
+	}
+	sort.Strings(idx.Shards)
+
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("failed to marshal search index: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+
+	return nil
+}
+
+// termFrequencies counts the weighted occurrences of every term of a
+// document and returns them with the number of terms
+func termFrequencies(doc Document) (map[string]int, int) {
+	frequencies := make(map[string]int)
+	length := 0
+	for _, term := range Tokenize(doc.Title) {
+		frequencies[term] += titleWeight
+		length++
+	}
+	for _, term := range Tokenize(strings.Join(doc.Tags, " ")) {
+		frequencies[term] += titleWeight
+		length++
+	}
+	for _, term := range Tokenize(doc.Text) {
+		frequencies[term]++
+		length++
+	}
+	return frequencies, length
+}
+
+// readIndex reads the documents of the existing index in dir that belong to
+// other sources than skip, together with the term frequencies of each. An
+// index that is missing or of another version is treated as empty.
+func readIndex(dir, skip string) ([]indexDocument, []map[string]int, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read search index: %w", err)
+	}
+
+	var old index
+	if err := json.Unmarshal(data, &old); err != nil || old.Version != indexVersion {
+		fmt.Println("Warning: rebuilding the search index from scratch, the existing one is outdated or corrupt")
+		return nil, nil, nil
+	}
+
+	// Renumber the kept documents
+	var documents []indexDocument
+	renumbered := make(map[int]int)
+	for i, doc := range old.Documents {
+		if doc.Source != skip {
+			renumbered[i] = len(documents)
+			documents = append(documents, doc)
+		}
+	}
+	if len(documents) == 0 {
+		return nil, nil, nil
+	}
+	terms := make([]map[string]int, len(documents))
+	for i := range terms {
+		terms[i] = make(map[string]int)
+	}
+
+	for _, key := range old.Shards {
+		data, err := os.ReadFile(filepath.Join(dir, "shards", key+".json"))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read search shard: %w", err)
+		}
+		var shard map[string][]posting
+		if err := json.Unmarshal(data, &shard); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse search shard %s: %w", key, err)
+		}
+		for term, postings := range shard {
+			for _, p := range postings {
+				if i, ok := renumbered[p[0]]; ok {
+					terms[i][term] = p[1]
+				}
+			}
+		}
+	}
+
+	return documents, terms, nil
+}
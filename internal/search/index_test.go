@@ -0,0 +1,139 @@
+package search
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var (
+	testPosts = []Document{
+		{Type: "post", Title: "Go 并发编程", URL: "/go-concurrency/", Text: "Goroutines and channels connect everything"},
+		{Type: "post", Title: "Hello", URL: "/hello/", Text: "secret draft notes"},
+	}
+	testMemos = []Document{
+		{Type: "memo", Title: "今天", URL: "/memos/1/", Text: "学习 Go 的 channels"},
+	}
+)
+
+// readTestIndex reads the index in dir and the postings of its shards
+func readTestIndex(t *testing.T, dir string) (index, map[string][]posting) {
+	t.Helper()
+
+	var idx index
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &idx); err != nil {
+		t.Fatal(err)
+	}
+
+	postings := make(map[string][]posting)
+	for _, key := range idx.Shards {
+		var shard map[string][]posting
+		data, err := os.ReadFile(filepath.Join(dir, "shards", key+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &shard); err != nil {
+			t.Fatal(err)
+		}
+		for term, p := range shard {
+			postings[term] = p
+		}
+	}
+	return idx, postings
+}
+
+// urls returns the URLs of the documents containing term
+func urls(idx index, postings map[string][]posting, term string) []string {
+	var found []string
+	for _, p := range postings[term] {
+		found = append(found, idx.Documents[p[0]].URL)
+	}
+	return found
+}
+
+func TestUpdateKeepsOtherSources(t *testing.T) {
+	dir := t.TempDir()
+	if err := Update(dir, "posts", testPosts); err != nil {
+		t.Fatal(err)
+	}
+	// Generating the memos on their own must not drop the posts
+	if err := Update(dir, "memos", testMemos); err != nil {
+		t.Fatal(err)
+	}
+
+	idx, postings := readTestIndex(t, dir)
+	if len(idx.Documents) != 3 {
+		t.Fatalf("documents = %d, want 3", len(idx.Documents))
+	}
+	if got, want := urls(idx, postings, "channel"), []string{"/memos/1/", "/go-concurrency/"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("documents with channel = %q, want %q", got, want)
+	}
+	if got, want := urls(idx, postings, "并发"), []string{"/go-concurrency/"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("documents with 并发 = %q, want %q", got, want)
+	}
+
+	// Replacing the posts drops the postings of removed ones
+	if err := Update(dir, "posts", testPosts[:1]); err != nil {
+		t.Fatal(err)
+	}
+	idx, postings = readTestIndex(t, dir)
+	if len(idx.Documents) != 2 {
+		t.Fatalf("documents = %d, want 2", len(idx.Documents))
+	}
+	if got := urls(idx, postings, "secret"); got != nil {
+		t.Errorf("documents with secret = %q, want none", got)
+	}
+	if got, want := urls(idx, postings, "学习"), []string{"/memos/1/"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("documents with 学习 = %q, want %q", got, want)
+	}
+}
+
+func TestUpdateIsOrderIndependent(t *testing.T) {
+	a, b := t.TempDir(), t.TempDir()
+	for _, step := range []struct {
+		dir, source string
+		documents   []Document
+	}{
+		{a, "posts", testPosts}, {a, "memos", testMemos},
+		{b, "memos", testMemos}, {b, "posts", testPosts},
+	} {
+		if err := Update(step.dir, step.source, step.documents); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	idxA, postingsA := readTestIndex(t, a)
+	idxB, postingsB := readTestIndex(t, b)
+	if !reflect.DeepEqual(idxA, idxB) || !reflect.DeepEqual(postingsA, postingsB) {
+		t.Error("index depends on the order the sources were updated in")
+	}
+}
+
+func TestUpdateDoesNotPublishText(t *testing.T) {
+	dir := t.TempDir()
+	// An index of an earlier version kept the full text in sources/
+	if err := os.MkdirAll(filepath.Join(dir, "sources"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := Update(dir, "posts", testPosts); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "sources")); !os.IsNotExist(err) {
+		t.Errorf("sources directory still exists: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret draft notes") {
+		t.Error("index.json contains the text of a document")
+	}
+}
@@ -0,0 +1,103 @@
+package search
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// searchJS is the client that queries the index in the browser
+const searchJS = "../../public/js/search.js"
+
+// jsResults are the outputs of the JavaScript ports for the same inputs
+type jsResults struct {
+	Stem      []string   `json:"stem"`
+	Tokenize  [][]string `json:"tokenize"`
+	ShardKey  []string   `json:"shardKey"`
+	TermShard []string   `json:"termShard"`
+}
+
+// TestJavaScriptMatches runs the test vectors through the ports in
+// public/js/search.js, which must agree with this package byte for byte or
+// queries silently miss. It is skipped when Node.js is not installed.
+func TestJavaScriptMatches(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node not found")
+	}
+
+	source, err := os.ReadFile(searchJS)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", searchJS, err)
+	}
+
+	var words, texts, terms, indexed []string
+	for _, tt := range stemTests {
+		words = append(words, tt.word)
+	}
+	for _, tt := range tokenizeTests {
+		texts = append(texts, tt.text)
+		indexed = append(indexed, Tokenize(tt.text)...)
+	}
+	for _, tt := range shardKeyTests {
+		terms = append(terms, tt.term)
+	}
+	input, err := json.Marshal(map[string][]string{
+		"words": words, "texts": texts, "terms": terms, "indexed": indexed,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// search.js registers a DOMContentLoaded handler when loaded
+	script := "globalThis.document = { addEventListener() {} };\n" +
+		string(source) + "\n" +
+		"const input = " + string(input) + ";\n" +
+		"console.log(JSON.stringify({\n" +
+		"  stem: input.words.map(stem),\n" +
+		"  tokenize: input.texts.map(tokenize),\n" +
+		"  shardKey: input.terms.map(shardKey),\n" +
+		"  termShard: input.indexed.map(shardKey),\n" +
+		"}));\n"
+	path := filepath.Join(t.TempDir(), "search.js")
+	if err := os.WriteFile(path, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := exec.Command(node, path).Output()
+	if err != nil {
+		t.Fatalf("node failed: %v", err)
+	}
+	var got jsResults
+	if err := json.Unmarshal(output, &got); err != nil {
+		t.Fatalf("failed to parse node output %q: %v", output, err)
+	}
+
+	for i, tt := range stemTests {
+		if got.Stem[i] != tt.want {
+			t.Errorf("stem(%q) = %q in JavaScript, want %q", tt.word, got.Stem[i], tt.want)
+		}
+	}
+	for i, tt := range tokenizeTests {
+		want := tt.want
+		if want == nil {
+			want = []string{}
+		}
+		if !reflect.DeepEqual(got.Tokenize[i], want) {
+			t.Errorf("tokenize(%q) = %q in JavaScript, want %q", tt.text, got.Tokenize[i], want)
+		}
+	}
+	for i, tt := range shardKeyTests {
+		if got.ShardKey[i] != tt.want {
+			t.Errorf("shardKey(%q) = %q in JavaScript, want %q", tt.term, got.ShardKey[i], tt.want)
+		}
+	}
+	for i, term := range indexed {
+		if want := shardKey(term); got.TermShard[i] != want {
+			t.Errorf("shardKey(%q) = %q in JavaScript, want %q", term, got.TermShard[i], want)
+		}
+	}
+}
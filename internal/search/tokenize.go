@@ -0,0 +1,72 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Tokenize splits text into index terms. Latin words are lowercased and
+// stemmed. Chinese, Japanese and Korean text has no spaces between words, so
+// it is split into overlapping bigrams instead: "有趣的代码" yields 有趣, 趣的,
+// 的代 and 代码, which matches any query substring of two or more characters.
+// A lone CJK character is kept as a term of its own. public/js/search.js
+// tokenizes queries the same way.
+func Tokenize(text string) []string {
+	var terms []string
+	var word []rune
+	var cjk []rune
+
+	flushWord := func() {
+		if len(word) > 0 {
+			terms = append(terms, Stem(string(word)))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		if len(cjk) == 1 {
+			terms = append(terms, string(cjk))
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			terms = append(terms, string(cjk[i:i+2]))
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+
+	return terms
+}
+
+// isCJK reports whether r is written without spaces between words
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// shardKey returns the name of the shard a term is stored in: the first two
+// characters of ASCII terms, and the 64 character block of the first
+// character of all others, so that CJK terms spread over a few hundred shards
+func shardKey(term string) string {
+	runes := []rune(term)
+	if runes[0] >= 0x80 {
+		return fmt.Sprintf("u%x", runes[0]>>6)
+	}
+	if len(runes) > 1 && runes[1] < 0x80 {
+		return string(runes[:2])
+	}
+	return string(runes[:1])
+}
@@ -0,0 +1,69 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+var tokenizeTests = []struct {
+	name string
+	text string
+	want []string
+}{
+	{"empty", "", nil},
+	{"punctuation only", "  ... !? ", nil},
+	{"latin words", "Connected Connections", []string{"connect", "connect"}},
+	{"numbers", "HTTP2 v1.2", []string{"http2", "v1", "2"}},
+	{"underscore", "running_fast", []string{"run", "fast"}},
+	{"accented latin", "Café résumé", []string{"café", "résumé"}},
+	{"greek", "ΟΔΟΣ", []string{"οδοσ"}},
+	{"dotted capital i", "İstanbul", []string{"istanbul"}},
+	{"cjk bigrams", "有趣的代码", []string{"有趣", "趣的", "的代", "代码"}},
+	{"cjk two characters", "代码", []string{"代码"}},
+	{"cjk single character", "码", []string{"码"}},
+	{"cjk single characters between spaces", "我 爱 你", []string{"我", "爱", "你"}},
+	{"cjk punctuation", "你好，世界！", []string{"你好", "世界"}},
+	{"japanese", "ひらがなカタカナ", []string{"ひら", "らが", "がな", "なカ", "カタ", "タカ", "カナ"}},
+	{"korean", "한국어", []string{"한국", "국어"}},
+	{"mixed script", "Go语言并发编程", []string{"go", "语言", "言并", "并发", "发编", "编程"}},
+	{"mixed script with spaces", "学习 Go 的 connections", []string{"学习", "go", "的", "connect"}},
+	{"latin between cjk", "用Rust写", []string{"用", "rust", "写"}},
+}
+
+func TestTokenize(t *testing.T) {
+	for _, tt := range tokenizeTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+var shardKeyTests = []struct {
+	term, want string
+}{
+	{"go", "go"},
+	{"connect", "co"},
+	{"a", "a"},
+	{"2", "2"},
+	{"v1", "v1"},
+	// A non-ASCII second character is left out of ASCII keys
+	{"aé", "a"},
+	// Other terms are sharded by the 64 character block of their first one
+	{"é", "u3"},
+	{"café", "ca"},
+	{"οδοσ", "ue"},
+	{"代码", "u13b"},
+	{"码", "u1e0"},
+	{"한국", "u355"},
+	{"😀", "u7d8"},
+}
+
+func TestShardKey(t *testing.T) {
+	for _, tt := range shardKeyTests {
+		if got := shardKey(tt.term); got != tt.want {
+			t.Errorf("shardKey(%q) = %q, want %q", tt.term, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// Slugify converts a string to a URL-friendly slug. Letters and digits of
+// any script are kept and lowercased, everything else collapses into single
+// hyphens. Non-ASCII slugs are percent-encoded when they end up in a URL.
+func Slugify(text string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+			continue
+		}
+		hyphen = true
+	}
+	return b.String()
+}
+
+// Transliterate replaces Chinese characters with their Pinyin, without tones,
+// separating syllables with spaces. Other characters are left untouched.
+func Transliterate(text string) string {
+	args := pinyin.NewArgs()
+
+	var b strings.Builder
+	for _, r := range text {
+		if !unicode.Is(unicode.Han, r) {
+			b.WriteRune(r)
+			continue
+		}
+		if syllables := pinyin.SinglePinyin(r, args); len(syllables) > 0 {
+			b.WriteString(" " + syllables[0] + " ")
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// maxSlugLength is the maximum length of a slug in bytes, well below the
+// 255 byte file name limit of common filesystems
+const maxSlugLength = 200
+
+// reservedNames are file names Windows refuses to create
+var reservedNames = []string{
+	"con", "prn", "aux", "nul",
+	"com1", "com2", "com3", "com4", "com5", "com6", "com7", "com8", "com9",
+	"lpt1", "lpt2", "lpt3", "lpt4", "lpt5", "lpt6", "lpt7", "lpt8", "lpt9",
+}
+
+// Slugger hands out slugs that are unique among the ones it generated,
+// appending -2, -3, ... on collision. Slugs are also safe to use as file
+// names: they are length limited and never a reserved device name.
+type Slugger struct {
+	// Pinyin transliterates Chinese characters before slugifying
+	Pinyin bool
+
+	used map[string]bool
+}
+
+// NewSlugger creates a new Slugger
+func NewSlugger(pinyin bool) *Slugger {
+	s := &Slugger{
+		Pinyin: pinyin,
+		used:   make(map[string]bool),
+	}
+	for _, name := range reservedNames {
+		s.used[name] = true
+	}
+	return s
+}
+
+// Slug returns a unique slug for text. fallback is used when text has no
+// letters or digits at all.
+func (s *Slugger) Slug(text, fallback string) string {
+	if s.Pinyin {
+		text = Transliterate(text)
+	}
+
+	slug := truncateSlug(Slugify(text))
+	if slug == "" {
+		slug = truncateSlug(Slugify(fallback))
+	}
+
+	return s.Reserve(slug)
+}
+
+// Reserve marks slug as used and returns it, suffixed if it was taken
+func (s *Slugger) Reserve(slug string) string {
+	unique := slug
+	for i := 2; s.used[unique]; i++ {
+		unique = slug + "-" + strconv.Itoa(i)
+	}
+	s.used[unique] = true
+	return unique
+}
+
+// truncateSlug shortens slug to maxSlugLength bytes without splitting a
+// character or leaving a trailing hyphen
+func truncateSlug(slug string) string {
+	if len(slug) <= maxSlugLength {
+		return slug
+	}
+
+	cut := 0
+	for i := range slug {
+		if i > maxSlugLength {
+			break
+		}
+		cut = i
+	}
+	return strings.TrimRight(slug[:cut], "-")
+}
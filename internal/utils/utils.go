@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"html"
 	"regexp"
-	"strings"
 )
 
 // PreviewContent extracts a preview of the content
@@ -34,25 +33,3 @@ func FormatDate(date string) string {
 	// In a real implementation, you might want to parse and reformat the date
 	return date
 }
-
-// Slugify converts a string to a URL-friendly slug
-func Slugify(text string) string {
-	// Convert to lowercase
-	slug := strings.ToLower(text)
-
-	// Replace spaces with hyphens
-	slug = strings.ReplaceAll(slug, " ", "-")
-
-	// Remove special characters
-	re := regexp.MustCompile(`[^a-z0-9\-]`)
-	slug = re.ReplaceAllString(slug, "")
-
-	// Replace multiple hyphens with single hyphen
-	re = regexp.MustCompile(`-+`)
-	slug = re.ReplaceAllString(slug, "-")
-
-	// Remove leading and trailing hyphens
-	slug = strings.Trim(slug, "-")
-
-	return slug
-}
\ No newline at end of file
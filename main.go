@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"pure/entities"
 	"pure/internal/fetcher"
 	"pure/internal/generator"
 )
@@ -17,9 +19,11 @@ import (
 // Config represents the application configuration
 type Config struct {
 	Github struct {
-		Owner string `mapstructure:"owner"`
-		Repo  string `mapstructure:"repo"`
-		Token string `mapstructure:"token"`
+		Owner   string        `mapstructure:"owner"`
+		Repo    string        `mapstructure:"repo"`
+		Token   string        `mapstructure:"token"`
+		Cache   string        `mapstructure:"cache"`
+		Timeout time.Duration `mapstructure:"timeout"`
 	} `mapstructure:"github"`
 	Telegram struct {
 		Channel string        `mapstructure:"channel"`
@@ -44,10 +48,59 @@ type Config struct {
 		Favicon  string `mapstructure:"favicon"`
 		Language string `mapstructure:"language"`
 	} `mapstructure:"site"`
+	Build struct {
+		Permalink string   `mapstructure:"permalink"`
+		Redirects []string `mapstructure:"redirects"`
+		Pinyin    bool     `mapstructure:"pinyin"`
+
+		PostsPerPage    int `mapstructure:"postsPerPage"`
+		TagPostsPerPage int `mapstructure:"tagPostsPerPage"`
+		MemosPerPage    int `mapstructure:"memosPerPage"`
+
+		FeedLimit       int  `mapstructure:"feedLimit"`
+		FeedFullContent bool `mapstructure:"feedFullContent"`
+
+		OGImages struct {
+			Enabled   bool   `mapstructure:"enabled"`
+			Font      string `mapstructure:"font"`
+			FontIndex int    `mapstructure:"fontIndex"`
+			Cache     string `mapstructure:"cache"`
+		} `mapstructure:"ogImages"`
+
+		TOC struct {
+			Enabled     bool `mapstructure:"enabled"`
+			MinHeadings int  `mapstructure:"minHeadings"`
+		} `mapstructure:"toc"`
+
+		ReadingSpeed struct {
+			CJK   int `mapstructure:"cjk"`
+			Latin int `mapstructure:"latin"`
+		} `mapstructure:"readingSpeed"`
+
+		RelatedPosts int `mapstructure:"relatedPosts"`
+
+		Publishing struct {
+			DraftLabels    []string `mapstructure:"draftLabels"`
+			UnlistedLabels []string `mapstructure:"unlistedLabels"`
+		} `mapstructure:"publishing"`
+
+		Featured []int `mapstructure:"featured"`
+	} `mapstructure:"build"`
+	Categories []struct {
+		Name     string `mapstructure:"name"`
+		Title    string `mapstructure:"title"`
+		Slug     string `mapstructure:"slug"`
+		Hidden   bool   `mapstructure:"hidden"`
+		Template string `mapstructure:"template"`
+	} `mapstructure:"categories"`
 }
 
 var (
-	cfgFile string
+	cfgFile       string
+	refreshCache  bool
+	strictMode    bool
+	demoMode      bool
+	includeDrafts bool
 )
 
 func init() {
@@ -93,58 +146,23 @@ var generateCmd = &cobra.Command{
 		outputPath := "./content"
 		templatePath := "./templates/*.html"
 
-		// 生成博客
-		fmt.Println("Fetching discussions from GitHub...")
-		githubToken := os.Getenv("GITHUB_TOKEN")
-		if githubToken == "" {
-			githubToken = config.Github.Token
-		}
-
-		discussions, err := fetcher.FetchDiscussions(githubToken, config.Github.Owner, config.Github.Repo)
-		if err != nil {
-			fmt.Printf("Warning: Failed to fetch discussions: %v\n", err)
-			fmt.Println("Generating site with sample data...")
-			discussions = getSampleDiscussions()
-		}
-
-		genConfig := generator.Config{
-			Site: generator.Site{
-				Title:       config.Site.Title,
-				URL:         config.Site.URL,
-				Description: config.Site.Description,
-				Author:      config.Site.Author,
-				Email:       config.Site.Email,
-				AboutID:     config.Site.AboutID,
-				Giscus: struct {
-					RepoID     string
-					Category   string
-					CategoryID string
-				}{
-					RepoID:     config.Site.Giscus.RepoID,
-					Category:   config.Site.Giscus.Category,
-					CategoryID: config.Site.Giscus.CategoryID,
-				},
-				Favicon:  config.Site.Favicon,
-				Language: config.Site.Language,
-			},
-			Github: generator.Github{
-				Owner: config.Github.Owner,
-				Repo:  config.Github.Repo,
-			},
-		}
-
-		siteGen, err := generator.NewSiteGenerator(genConfig, templatePath, outputPath)
-		if err != nil {
-			log.Fatalf("Failed to create site generator: %v", err)
-		}
+		// 本次生成的 sitemap，只在 robots.txt 中列出这些
+		var sitemaps []string
 
-		fmt.Println("Generating blog pages...")
-		if err := siteGen.Generate(discussions); err != nil {
-			log.Fatalf("Failed to generate blog: %v", err)
+		// 生成博客
+		if discussions, ok := loadDiscussions(config); ok {
+			fmt.Println("Generating blog pages...")
+			sitemap, err := generateBlog(config, discussions, templatePath, outputPath)
+			if err != nil {
+				log.Fatalf("Failed to generate blog: %v", err)
+			}
+			sitemaps = append(sitemaps, sitemap)
 		}
 
 		// 生成碎碎念（如果配置了 Telegram）
-		if config.Telegram.Channel != "" {
+		if config.Telegram.Channel != "" && demoMode {
+			fmt.Println("Skipping memos in demo mode")
+		} else if config.Telegram.Channel != "" {
 			fmt.Println("Fetching memos from Telegram...")
 
 			var sinceTime, untilTime time.Time
@@ -171,7 +189,9 @@ var generateCmd = &cobra.Command{
 				untilTime,
 			)
 			notes, err := telegramFetcher.FetchNotes()
-			if err != nil {
+			if err != nil && strictMode {
+				log.Fatalf("Failed to fetch memos: %v", err)
+			} else if err != nil {
 				fmt.Printf("Warning: Failed to fetch memos: %v\n", err)
 			} else {
 				fmt.Printf("Found %d memos\n", len(notes))
@@ -181,6 +201,9 @@ var generateCmd = &cobra.Command{
 					Description: config.Site.Description,
 					URL:         config.Site.URL,
 					Author:      config.Site.Author,
+					PerPage:     memosPerPage(config),
+					FeedLimit:   config.Build.FeedLimit,
+					OGImages:    ogImagesConfig(config),
 				}
 
 				notesGen, err := generator.NewNotesGenerator(notesConfig, templatePath, outputPath)
@@ -192,9 +215,16 @@ var generateCmd = &cobra.Command{
 				if err := notesGen.Generate(notes); err != nil {
 					log.Fatalf("Failed to generate memos: %v", err)
 				}
+				if sitemap := notesGen.Sitemap(); sitemap != "" {
+					sitemaps = append(sitemaps, sitemap)
+				}
 			}
 		}
 
+		if err := generator.WriteRobots(outputPath, config.Site.URL, sitemaps); err != nil {
+			log.Fatalf("Failed to generate robots.txt: %v", err)
+		}
+
 		fmt.Println("Site generated successfully in 'content' directory!")
 	},
 }
@@ -260,6 +290,9 @@ var genNotesCmd = &cobra.Command{
 			Description: config.Site.Description,
 			URL:         config.Site.URL,
 			Author:      config.Site.Author,
+			PerPage:     memosPerPage(config),
+			FeedLimit:   config.Build.FeedLimit,
+			OGImages:    ogImagesConfig(config),
 		}
 
 		notesGen, err := generator.NewNotesGenerator(notesConfig, templatePath, outputPath)
@@ -287,63 +320,25 @@ var previewCmd = &cobra.Command{
 			log.Fatalf("Unable to decode into struct: %v", err)
 		}
 
-		// 获取 GitHub token，优先使用环境变量中的 GITHUB_TOKEN，如果不存在则使用配置文件中的 token
-		githubToken := os.Getenv("GITHUB_TOKEN")
-		if githubToken == "" {
-			githubToken = config.Github.Token
-		}
-
 		// 获取数据
-		fmt.Println("Fetching discussions from GitHub...")
-		discussions, err := fetcher.FetchDiscussions(githubToken, config.Github.Owner, config.Github.Repo)
-		if err != nil {
-			fmt.Printf("Warning: Failed to fetch discussions: %v\n", err)
-			fmt.Println("Generating site with sample data...")
-			// 使用示例数据
-			discussions = getSampleDiscussions()
-		}
-
-		// 初始化生成器
-		genConfig := generator.Config{
-			Site: generator.Site{
-				Title:       config.Site.Title,
-				URL:         config.Site.URL,
-				Description: config.Site.Description,
-				Author:      config.Site.Author,
-				Email:       config.Site.Email,
-				AboutID:     config.Site.AboutID,
-				Giscus: struct {
-					RepoID     string
-					Category   string
-					CategoryID string
-				}{
-					RepoID:     config.Site.Giscus.RepoID,
-					Category:   config.Site.Giscus.Category,
-					CategoryID: config.Site.Giscus.CategoryID,
-				},
-				Favicon:  config.Site.Favicon,
-				Language: config.Site.Language,
-			},
-			Github: generator.Github{
-				Owner: config.Github.Owner,
-				Repo:  config.Github.Repo,
-			},
+		discussions, ok := loadDiscussions(config)
+		if !ok {
+			log.Fatalf("No discussions to preview")
 		}
 
 		// 创建输出目录
 		outputPath := "./content"
 		templatePath := "./templates/*.html"
 
-		siteGen, err := generator.NewSiteGenerator(genConfig, templatePath, outputPath)
-		if err != nil {
-			log.Fatalf("Failed to create site generator: %v", err)
-		}
-
 		// 生成网站
 		fmt.Println("Generating site files...")
-		if err := siteGen.Generate(discussions); err != nil {
+		sitemap, err := generateBlog(config, discussions, templatePath, outputPath)
+		if err != nil {
 			log.Fatalf("Failed to generate site: %v", err)
 		}
+		if err := generator.WriteRobots(outputPath, config.Site.URL, []string{sitemap}); err != nil {
+			log.Fatalf("Failed to generate robots.txt: %v", err)
+		}
 
 		fmt.Println("Site generated successfully in 'content' directory!")
 
@@ -353,12 +348,171 @@ var previewCmd = &cobra.Command{
 }
 
 func init() {
+	generateCmd.Flags().BoolVar(&refreshCache, "refresh", false, "ignore the discussion cache and refetch everything")
+	previewCmd.Flags().BoolVar(&refreshCache, "refresh", false, "ignore the discussion cache and refetch everything")
+	previewCmd.Flags().BoolVar(&includeDrafts, "drafts", false, "include drafts and scheduled posts")
+
+	// CI is set by GitHub Actions and most other CI systems
+	for _, cmd := range []*cobra.Command{generateCmd, previewCmd} {
+		cmd.Flags().BoolVar(&strictMode, "strict", os.Getenv("CI") != "", "fail when a configured source cannot be fetched (default on in CI)")
+		cmd.Flags().BoolVar(&demoMode, "demo", false, "build the site from sample data instead of fetching")
+	}
+
 	rootCmd.AddCommand(generateCmd)
 	rootCmd.AddCommand(serveCmd)
 	rootCmd.AddCommand(previewCmd)
 	rootCmd.AddCommand(genNotesCmd)
 }
 
+// loadDiscussions returns the discussions to build the site from. Sample data
+// is only used under --demo. When fetching fails, --strict aborts the build;
+// otherwise a warning is printed and ok is false so the blog pages are skipped.
+func loadDiscussions(config Config) (discussions []fetcher.Discussion, ok bool) {
+	if demoMode {
+		fmt.Println("Generating site with sample data...")
+		return getSampleDiscussions(), true
+	}
+
+	fmt.Println("Fetching discussions from GitHub...")
+	discussions, err := fetchDiscussions(config)
+	if err != nil && strictMode {
+		log.Fatalf("Failed to fetch discussions: %v", err)
+	}
+	if err != nil {
+		fmt.Printf("Warning: Failed to fetch discussions: %v\n", err)
+		fmt.Println("Skipping blog pages, pass --demo to build from sample data")
+		return nil, false
+	}
+
+	return discussions, true
+}
+
+// generateBlog generates the blog pages from discussions and returns the
+// site-relative path of the sitemap it wrote
+func generateBlog(config Config, discussions []fetcher.Discussion, templatePath, outputPath string) (string, error) {
+	genConfig := generator.Config{
+		Site: generator.Site{
+			Title:       config.Site.Title,
+			URL:         config.Site.URL,
+			Description: config.Site.Description,
+			Author:      config.Site.Author,
+			Email:       config.Site.Email,
+			AboutID:     config.Site.AboutID,
+			Giscus: struct {
+				RepoID     string
+				Category   string
+				CategoryID string
+			}{
+				RepoID:     config.Site.Giscus.RepoID,
+				Category:   config.Site.Giscus.Category,
+				CategoryID: config.Site.Giscus.CategoryID,
+			},
+			Favicon:  config.Site.Favicon,
+			Language: config.Site.Language,
+		},
+		Github: generator.Github{
+			Owner: config.Github.Owner,
+			Repo:  config.Github.Repo,
+		},
+		Telegram: generator.Telegram{
+			Channel: config.Telegram.Channel,
+			Host:    config.Telegram.Host,
+		},
+		Build: generator.Build{
+			Permalink: config.Build.Permalink,
+			Redirects: config.Build.Redirects,
+			Pinyin:    config.Build.Pinyin,
+
+			PostsPerPage:    config.Build.PostsPerPage,
+			TagPostsPerPage: config.Build.TagPostsPerPage,
+
+			FeedLimit:       config.Build.FeedLimit,
+			FeedFullContent: config.Build.FeedFullContent,
+
+			OGImages: ogImagesConfig(config),
+
+			TOC: generator.TOC{
+				Enabled:     config.Build.TOC.Enabled,
+				MinHeadings: config.Build.TOC.MinHeadings,
+			},
+			ReadingSpeed: generator.ReadingSpeed{
+				CJK:   config.Build.ReadingSpeed.CJK,
+				Latin: config.Build.ReadingSpeed.Latin,
+			},
+			RelatedPosts: config.Build.RelatedPosts,
+			Publishing: generator.Publishing{
+				DraftLabels:    config.Build.Publishing.DraftLabels,
+				UnlistedLabels: config.Build.Publishing.UnlistedLabels,
+				Drafts:         includeDrafts,
+			},
+			Featured: config.Build.Featured,
+		},
+	}
+	for _, category := range config.Categories {
+		genConfig.Categories = append(genConfig.Categories, generator.Category{
+			Name:     category.Name,
+			Title:    category.Title,
+			Slug:     category.Slug,
+			Hidden:   category.Hidden,
+			Template: category.Template,
+		})
+	}
+
+	siteGen, err := generator.NewSiteGenerator(genConfig, templatePath, outputPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create site generator: %w", err)
+	}
+
+	if err := siteGen.Generate(discussions); err != nil {
+		return "", err
+	}
+	return siteGen.Sitemap(), nil
+}
+
+// memosPerPage returns the page size of the memos, defaulting to the page
+// size of posts like tag pages do
+func memosPerPage(config Config) int {
+	if config.Build.MemosPerPage > 0 {
+		return config.Build.MemosPerPage
+	}
+	return config.Build.PostsPerPage
+}
+
+// ogImagesConfig returns the Open Graph card configuration shared by the blog
+// and memos generators. Under --strict, cards that cannot be drawn fail the build.
+func ogImagesConfig(config Config) generator.OGImages {
+	return generator.OGImages{
+		Enabled:   config.Build.OGImages.Enabled,
+		Font:      config.Build.OGImages.Font,
+		FontIndex: config.Build.OGImages.FontIndex,
+		Cache:     config.Build.OGImages.Cache,
+		Strict:    strictMode,
+	}
+}
+
+// fetchDiscussions fetches all discussions, syncing incrementally through the
+// on-disk cache when github.cache is configured
+func fetchDiscussions(config Config) ([]fetcher.Discussion, error) {
+	// 获取 GitHub token，优先使用环境变量中的 GITHUB_TOKEN，如果不存在则使用配置文件中的 token
+	githubToken := os.Getenv("GITHUB_TOKEN")
+	if githubToken == "" {
+		githubToken = config.Github.Token
+	}
+
+	ctx := context.Background()
+	if config.Github.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Github.Timeout)
+		defer cancel()
+	}
+
+	if config.Github.Cache == "" {
+		return fetcher.FetchDiscussions(ctx, githubToken, config.Github.Owner, config.Github.Repo)
+	}
+
+	return fetcher.SyncDiscussions(ctx, githubToken, config.Github.Owner, config.Github.Repo, config.Github.Cache, refreshCache)
+}
+
 func getSampleDiscussions() []fetcher.Discussion {
 	// 创建一些示例讨论数据
 	time1 := time.Date(2025, 9, 15, 10, 30, 0, 0, time.UTC)
@@ -387,6 +541,7 @@ func getSampleDiscussions() []fetcher.Discussion {
 			},
 			CreatedAt: time1,
 			URL:       "http://www.leetao94.cn/posts/1",
+			Pinned:    true,
 		},
 		{
 			ID:     "2",
@@ -410,6 +565,24 @@ func getSampleDiscussions() []fetcher.Discussion {
 			},
 			CreatedAt: time2,
 			URL:       "http://www.leetao94.cn/posts/2",
+			Comments: []entities.Comment{
+				{
+					ID:        "c1",
+					Body:      "Great introduction! Could you also cover `select`?",
+					Author:    "reader",
+					CreatedAt: time2.Add(2 * time.Hour),
+					URL:       "http://www.leetao94.cn/posts/2#c1",
+					Replies: []entities.Comment{
+						{
+							ID:        "c2",
+							Body:      "Sure, it's coming in the next post.",
+							Author:    "Leetao",
+							CreatedAt: time2.Add(3 * time.Hour),
+							URL:       "http://www.leetao94.cn/posts/2#c2",
+						},
+					},
+				},
+			},
 		},
 		{
 			ID:     "3",
@@ -434,6 +607,43 @@ func getSampleDiscussions() []fetcher.Discussion {
 			CreatedAt: time3,
 			URL:       "http://www.leetao94.cn/posts/3",
 		},
+		{
+			ID:     "4",
+			Number: 4,
+			Title:  "How do I subscribe to new posts?",
+			Body:   "Is there a way to get notified when a new post is published?",
+			Author: "reader",
+			Category: struct {
+				ID   string
+				Name string
+			}{
+				ID:   "3",
+				Name: "Q&A",
+			},
+			CreatedAt: time3.Add(-24 * time.Hour),
+			URL:       "http://www.leetao94.cn/posts/4",
+			Comments: []entities.Comment{
+				{
+					ID:        "c3",
+					Body:      "Subscribe to the RSS feed at `/rss.xml`, or the Atom and JSON feeds next to it.",
+					Author:    "Leetao",
+					CreatedAt: time3.Add(-20 * time.Hour),
+					URL:       "http://www.leetao94.cn/posts/4#c3",
+					IsAnswer:  true,
+				},
+			},
+			Answerable: true,
+			IsAnswered: true,
+			Answer: &entities.Comment{
+				ID:        "c3",
+				Body:      "Subscribe to the RSS feed at `/rss.xml`, or the Atom and JSON feeds next to it.",
+				Author:    "Leetao",
+				CreatedAt: time3.Add(-20 * time.Hour),
+				URL:       "http://www.leetao94.cn/posts/4#c3",
+				IsAnswer:  true,
+			},
+			AnswerChosenAt: time3.Add(-19 * time.Hour),
+		},
 	}
 }
 
@@ -0,0 +1,445 @@
+/**
+ * 站内搜索
+ * 读取构建时生成的倒排索引 /search/index.json，只下载查询词所在的分片，
+ * 用 BM25 排序并高亮匹配内容。
+ * 分词和词干提取必须与 internal/search 保持一致。
+ */
+
+const SEARCH_BASE = '/search/';
+const BM25_K1 = 1.2;
+const BM25_B = 0.75;
+const MAX_RESULTS = 50;
+
+/**
+ * Porter 词干提取，移植自 internal/search/stem.go
+ */
+class Stemmer {
+  constructor(word) {
+    this.b = word;
+    this.k = word.length - 1;
+    this.j = 0;
+  }
+
+  // b[i] 是否为辅音
+  cons(i) {
+    switch (this.b[i]) {
+      case 'a': case 'e': case 'i': case 'o': case 'u':
+        return false;
+      case 'y':
+        return i === 0 ? true : !this.cons(i - 1);
+    }
+    return true;
+  }
+
+  // b[0..j] 中辅音序列的个数
+  m() {
+    let n = 0;
+    let i = 0;
+    for (;;) {
+      if (i > this.j) return n;
+      if (!this.cons(i)) break;
+      i++;
+    }
+    i++;
+    for (;;) {
+      for (;;) {
+        if (i > this.j) return n;
+        if (this.cons(i)) break;
+        i++;
+      }
+      i++;
+      n++;
+      for (;;) {
+        if (i > this.j) return n;
+        if (!this.cons(i)) break;
+        i++;
+      }
+      i++;
+    }
+  }
+
+  vowelInStem() {
+    for (let i = 0; i <= this.j; i++) {
+      if (!this.cons(i)) return true;
+    }
+    return false;
+  }
+
+  doubleC(i) {
+    return i >= 1 && this.b[i] === this.b[i - 1] && this.cons(i);
+  }
+
+  cvc(i) {
+    if (i < 2 || !this.cons(i) || this.cons(i - 1) || !this.cons(i - 2)) return false;
+    const c = this.b[i];
+    return c !== 'w' && c !== 'x' && c !== 'y';
+  }
+
+  ends(suffix) {
+    const n = suffix.length;
+    if (n > this.k + 1 || this.b.slice(this.k + 1 - n, this.k + 1) !== suffix) return false;
+    this.j = this.k - n;
+    return true;
+  }
+
+  setTo(suffix) {
+    this.b = this.b.slice(0, this.j + 1) + suffix;
+    this.k = this.j + suffix.length;
+  }
+
+  r(suffix) {
+    if (this.m() > 0) this.setTo(suffix);
+  }
+
+  step1ab() {
+    if (this.b[this.k] === 's') {
+      if (this.ends('sses')) {
+        this.k -= 2;
+      } else if (this.ends('ies')) {
+        this.setTo('i');
+      } else if (this.b[this.k - 1] !== 's') {
+        this.k--;
+      }
+    }
+    if (this.ends('eed')) {
+      if (this.m() > 0) this.k--;
+    } else if ((this.ends('ed') || this.ends('ing')) && this.vowelInStem()) {
+      this.k = this.j;
+      if (this.ends('at')) {
+        this.setTo('ate');
+      } else if (this.ends('bl')) {
+        this.setTo('ble');
+      } else if (this.ends('iz')) {
+        this.setTo('ize');
+      } else if (this.doubleC(this.k)) {
+        this.k--;
+        const c = this.b[this.k];
+        if (c === 'l' || c === 's' || c === 'z') this.k++;
+      } else if (this.m() === 1 && this.cvc(this.k)) {
+        this.setTo('e');
+      }
+    }
+  }
+
+  step1c() {
+    if (this.ends('y') && this.vowelInStem()) {
+      this.b = this.b.slice(0, this.k) + 'i' + this.b.slice(this.k + 1);
+    }
+  }
+
+  replaceFirst(...pairs) {
+    for (let i = 0; i < pairs.length; i += 2) {
+      if (this.ends(pairs[i])) {
+        this.r(pairs[i + 1]);
+        return;
+      }
+    }
+  }
+
+  step2() {
+    switch (this.b[this.k - 1]) {
+      case 'a': this.replaceFirst('ational', 'ate', 'tional', 'tion'); break;
+      case 'c': this.replaceFirst('enci', 'ence', 'anci', 'ance'); break;
+      case 'e': this.replaceFirst('izer', 'ize'); break;
+      case 'l': this.replaceFirst('bli', 'ble', 'alli', 'al', 'entli', 'ent', 'eli', 'e', 'ousli', 'ous'); break;
+      case 'o': this.replaceFirst('ization', 'ize', 'ation', 'ate', 'ator', 'ate'); break;
+      case 's': this.replaceFirst('alism', 'al', 'iveness', 'ive', 'fulness', 'ful', 'ousness', 'ous'); break;
+      case 't': this.replaceFirst('aliti', 'al', 'iviti', 'ive', 'biliti', 'ble'); break;
+      case 'g': this.replaceFirst('logi', 'log'); break;
+    }
+  }
+
+  step3() {
+    switch (this.b[this.k]) {
+      case 'e': this.replaceFirst('icate', 'ic', 'ative', '', 'alize', 'al'); break;
+      case 'i': this.replaceFirst('iciti', 'ic'); break;
+      case 'l': this.replaceFirst('ical', 'ic', 'ful', ''); break;
+      case 's': this.replaceFirst('ness', ''); break;
+    }
+  }
+
+  step4() {
+    let suffixes;
+    switch (this.b[this.k - 1]) {
+      case 'a': suffixes = ['al']; break;
+      case 'c': suffixes = ['ance', 'ence']; break;
+      case 'e': suffixes = ['er']; break;
+      case 'i': suffixes = ['ic']; break;
+      case 'l': suffixes = ['able', 'ible']; break;
+      case 'n': suffixes = ['ant', 'ement', 'ment', 'ent']; break;
+      case 'o':
+        if (this.ends('ion') && this.j >= 0 && (this.b[this.j] === 's' || this.b[this.j] === 't')) {
+          suffixes = null;
+          break;
+        }
+        suffixes = ['ou'];
+        break;
+      case 's': suffixes = ['ism']; break;
+      case 't': suffixes = ['ate', 'iti']; break;
+      case 'u': suffixes = ['ous']; break;
+      case 'v': suffixes = ['ive']; break;
+      case 'z': suffixes = ['ize']; break;
+      default: return;
+    }
+
+    if (suffixes && !suffixes.some((suffix) => this.ends(suffix))) return;
+
+    if (this.m() > 1) this.k = this.j;
+  }
+
+  step5() {
+    this.j = this.k;
+    if (this.b[this.k] === 'e') {
+      const a = this.m();
+      if (a > 1 || (a === 1 && !this.cvc(this.k - 1))) this.k--;
+    }
+    if (this.b[this.k] === 'l' && this.doubleC(this.k) && this.m() > 1) this.k--;
+  }
+}
+
+function stem(word) {
+  if (word.length <= 2 || !/^[a-z]+$/.test(word)) return word;
+
+  const s = new Stemmer(word);
+  s.step1ab();
+  if (s.k > 0) {
+    s.step1c();
+    s.step2();
+    s.step3();
+    s.step4();
+    s.step5();
+  }
+  return s.b.slice(0, s.k + 1);
+}
+
+// 逐字转小写并只取第一个字符，与 Go 的 strings.ToLower 一致：
+// 整串转换时 Σ 在词尾会变成 ς，İ 会变成 i 加上组合附加符号
+const lowerChar = (c) => String.fromCodePoint(c.toLowerCase().codePointAt(0));
+
+const CJK_RE = /[\p{Script=Han}\p{Script=Hiragana}\p{Script=Katakana}\p{Script=Hangul}]/u;
+const WORD_RE = /[\p{L}\p{N}]/u;
+
+/**
+ * 分词：英文单词转小写并提取词干，中日韩文字切分为相邻二元组
+ */
+function tokenize(text) {
+  const terms = [];
+  let word = '';
+  let cjk = [];
+
+  const flushWord = () => {
+    if (word) terms.push(stem(word));
+    word = '';
+  };
+  const flushCJK = () => {
+    if (cjk.length === 1) terms.push(cjk[0]);
+    for (let i = 0; i + 1 < cjk.length; i++) terms.push(cjk[i] + cjk[i + 1]);
+    cjk = [];
+  };
+
+  for (const c of Array.from(text, lowerChar)) {
+    if (CJK_RE.test(c)) {
+      flushWord();
+      cjk.push(c);
+    } else if (WORD_RE.test(c)) {
+      flushCJK();
+      word += c;
+    } else {
+      flushWord();
+      flushCJK();
+    }
+  }
+  flushWord();
+  flushCJK();
+
+  return terms;
+}
+
+// 词所在分片的名称，与 internal/search 的 shardKey 相同
+function shardKey(term) {
+  const chars = Array.from(term);
+  const first = chars[0].codePointAt(0);
+  if (first >= 0x80) return 'u' + (first >> 6).toString(16);
+  if (chars.length > 1 && chars[1].codePointAt(0) < 0x80) return chars[0] + chars[1];
+  return chars[0];
+}
+
+const escapeHTML = (s) => s.replace(/[&<>"']/g, (c) => ({
+  '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;'
+}[c]));
+
+class Search {
+  constructor() {
+    this.index = null;
+    this.shards = new Map();
+  }
+
+  async loadIndex() {
+    if (!this.index) {
+      const response = await fetch(SEARCH_BASE + 'index.json');
+      if (!response.ok) throw new Error('Failed to load search index');
+      this.index = await response.json();
+      this.available = new Set(this.index.shards);
+    }
+    return this.index;
+  }
+
+  // 只下载查询需要的分片，并缓存已下载的分片
+  loadShard(key) {
+    if (!this.available.has(key)) return Promise.resolve({});
+    if (!this.shards.has(key)) {
+      this.shards.set(key, fetch(SEARCH_BASE + 'shards/' + encodeURIComponent(key) + '.json')
+        .then((response) => (response.ok ? response.json() : {}))
+        .catch(() => ({})));
+    }
+    return this.shards.get(key);
+  }
+
+  async query(text) {
+    const terms = [...new Set(tokenize(text))];
+    if (terms.length === 0) return [];
+
+    const index = await this.loadIndex();
+    const shards = await Promise.all(terms.map((term) => this.loadShard(shardKey(term))));
+
+    const total = index.documents.length;
+    const scores = new Map();
+    terms.forEach((term, i) => {
+      const postings = shards[i][term] || [];
+      if (postings.length === 0) return;
+      const idf = Math.log(1 + (total - postings.length + 0.5) / (postings.length + 0.5));
+      for (const [doc, tf] of postings) {
+        const length = index.documents[doc].length;
+        const norm = tf + BM25_K1 * (1 - BM25_B + BM25_B * length / (index.averageLength || 1));
+        const score = scores.get(doc) || { matched: 0, score: 0 };
+        score.matched++;
+        score.score += idf * tf * (BM25_K1 + 1) / norm;
+        scores.set(doc, score);
+      }
+    });
+
+    // 匹配的查询词越多越靠前，其次按 BM25 得分排序
+    return [...scores.entries()]
+      .sort((a, b) => b[1].matched - a[1].matched || b[1].score - a[1].score || a[0] - b[0])
+      .slice(0, MAX_RESULTS)
+      .map(([doc]) => index.documents[doc]);
+  }
+}
+
+// 高亮文本中与查询匹配的部分，结果已转义可直接插入 HTML
+function highlight(text, query) {
+  const words = [];
+  for (const part of query.toLowerCase().split(/[^\p{L}\p{N}]+/u)) {
+    if (!part) continue;
+    for (const piece of part.split(/([\p{Script=Han}\p{Script=Hiragana}\p{Script=Katakana}\p{Script=Hangul}]+)/u)) {
+      // 单个字母到处都是，不作高亮
+      if (!piece || (piece.length < 2 && !CJK_RE.test(piece))) continue;
+      words.push(piece);
+      if (CJK_RE.test(piece)) {
+        // 与索引一样按二元组匹配，查询不必是原文的连续片段
+        const chars = Array.from(piece);
+        for (let i = 0; i + 1 < chars.length; i++) words.push(chars[i] + chars[i + 1]);
+      } else {
+        // 英文同时匹配词干，如 connecting 也高亮 connected 中的 connect
+        words.push(stem(piece));
+      }
+    }
+  }
+  const lower = text.toLowerCase();
+  if (words.length === 0 || lower.length !== text.length) return escapeHTML(text);
+
+  // 先标记所有匹配的字符，重叠的匹配会合并为一段
+  const marked = new Array(text.length).fill(false);
+  for (const word of words) {
+    for (let i = lower.indexOf(word); i !== -1; i = lower.indexOf(word, i + 1)) {
+      marked.fill(true, i, i + word.length);
+    }
+  }
+
+  let html = '';
+  let start = 0;
+  for (let i = 1; i <= text.length; i++) {
+    if (i === text.length || marked[i] !== marked[start]) {
+      const part = escapeHTML(text.slice(start, i));
+      html += marked[start] ? '<mark>' + part + '</mark>' : part;
+      start = i;
+    }
+  }
+  return html;
+}
+
+function renderResults(container, status, results, query) {
+  container.innerHTML = '';
+  if (results.length === 0) {
+    status.textContent = `No results for "${query}"`;
+    return;
+  }
+
+  status.textContent = `${results.length} result${results.length === 1 ? '' : 's'} for "${query}"`;
+  for (const doc of results) {
+    const item = document.createElement('li');
+    item.className = 'search-result';
+    item.innerHTML = `
+      <h2 class="post-title"><a href="${escapeHTML(doc.url)}">${highlight(doc.title, query)}</a></h2>
+      <p class="post-meta">${doc.type === 'memo' ? 'Memo' : 'Post'} · ${escapeHTML(doc.date)}</p>
+      <p class="search-summary">${highlight(doc.summary, query)}</p>
+    `;
+    container.appendChild(item);
+  }
+}
+
+document.addEventListener('DOMContentLoaded', () => {
+  const form = document.getElementById('search-form');
+  const input = document.getElementById('search-input');
+  const status = document.getElementById('search-status');
+  const container = document.getElementById('search-results');
+  if (!form || !input || !container) return;
+
+  const search = new Search();
+  let latest = 0;
+
+  const run = async (query) => {
+    const id = ++latest;
+    query = query.trim();
+    if (!query) {
+      container.innerHTML = '';
+      status.textContent = '';
+      return;
+    }
+    try {
+      const results = await search.query(query);
+      // 忽略已过期的查询结果
+      if (id === latest) renderResults(container, status, results, query);
+    } catch (error) {
+      console.error(error);
+      status.textContent = 'Search is not available right now.';
+    }
+  };
+
+  let timer;
+  input.addEventListener('input', () => {
+    clearTimeout(timer);
+    timer = setTimeout(() => {
+      const url = new URL(window.location.href);
+      if (input.value.trim()) {
+        url.searchParams.set('q', input.value.trim());
+      } else {
+        url.searchParams.delete('q');
+      }
+      history.replaceState(null, '', url);
+      run(input.value);
+    }, 200);
+  });
+
+  form.addEventListener('submit', (event) => {
+    event.preventDefault();
+    clearTimeout(timer);
+    run(input.value);
+  });
+
+  const initial = new URLSearchParams(window.location.search).get('q');
+  if (initial) {
+    input.value = initial;
+    run(initial);
+  }
+});